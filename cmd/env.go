@@ -67,8 +67,16 @@ var envCmd = &cobra.Command{
 			}
 		}
 
+		var err error
+
+		containerTypes, err = resource.ParseContainerTypes(containerType)
+		if err != nil {
+			return fmt.Errorf("container type: %w", err)
+		}
+
 		envGetter := env.NewEnvGetter(kind, name).
-			WithContainerRegexp(containerRegexp)
+			WithContainerRegexp(containerRegexp).
+			WithContainerTypes(containerTypes)
 
 		clients.Execute(ctx, envGetter.Get)
 
@@ -80,4 +88,5 @@ func initEnv() {
 	flags := envCmd.Flags()
 
 	flags.StringVarP(&container, "container", "c", "", "Filter container's name by regexp, default to all containers")
+	flags.StringSliceVarP(&containerType, "container-type", "", nil, "Filter container's type (init, main, sidecar, ephemeral), default to all types")
 }
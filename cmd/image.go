@@ -2,19 +2,53 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"syscall"
+	"time"
 
 	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/output"
 	"github.com/ViBiOh/kmux/pkg/resource"
+	"github.com/ViBiOh/kmux/pkg/table"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
 )
 
+var (
+	showMatrix  bool
+	showRunning bool
+	showHistory bool
+
+	allImages         bool
+	imageRegistries   string
+	imageRepositories string
+	imageTags         string
+)
+
+// imageItem is an image of the namespace-wide inventory
+type imageItem struct {
+	Context    string `json:"context,omitempty"`
+	Namespace  string `json:"namespace"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Container  string `json:"container"`
+	Image      string `json:"image"`
+	Registry   string `json:"registry"`
+	Repository string `json:"repository"`
+	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
+}
+
 var imageCmd = &cobra.Command{
-	Use:   "image TYPE NAME",
+	Use:   "image TYPE NAME | --all",
 	Short: "Get all image names of containers for a given resource",
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		if len(args) == 0 {
@@ -45,11 +79,14 @@ var imageCmd = &cobra.Command{
 
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
-	Args: cobra.MatchAll(cobra.ExactArgs(2), cobra.OnlyValidArgs),
-	RunE: func(cmd *cobra.Command, args []string) error {
-		kind := args[0]
-		name := args[1]
+	Args: func(cmd *cobra.Command, args []string) error {
+		if allImages {
+			return cobra.NoArgs(cmd, args)
+		}
 
+		return cobra.MatchAll(cobra.ExactArgs(2), cobra.OnlyValidArgs)(cmd, args)
+	},
+	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithCancel(cmd.Context())
 		defer cancel()
 
@@ -67,14 +104,63 @@ var imageCmd = &cobra.Command{
 			}
 		}
 
+		var err error
+
+		containerTypes, err = resource.ParseContainerTypes(containerType)
+		if err != nil {
+			return fmt.Errorf("container type: %w", err)
+		}
+
+		if allImages {
+			return inventoryImages(ctx)
+		}
+
+		kind := args[0]
+		name := args[1]
+
+		if showHistory {
+			return imageHistory(ctx, kind, name)
+		}
+
+		if showRunning {
+			return runningImages(ctx, cmd, kind, name)
+		}
+
+		if showMatrix {
+			matrix := newImageMatrix(clients.Names())
+
+			clients.Execute(ctx, func(ctx context.Context, kube client.Kube) error {
+				podSpec, err := resource.GetPodSpec(ctx, kube, kind, name)
+				if err != nil {
+					return err
+				}
+
+				for _, container := range resource.Containers(podSpec) {
+					if resource.IsContainedSelected(container, containerRegexp, containerTypes) {
+						matrix.add(container.Name, kube.Name, container.Image)
+					}
+				}
+
+				return nil
+			})
+
+			if drifts := matrix.print(); drifts != 0 {
+				cmd.SilenceUsage = true
+
+				return fmt.Errorf("image drift found in %d container(s)", drifts)
+			}
+
+			return nil
+		}
+
 		clients.Execute(ctx, func(ctx context.Context, kube client.Kube) error {
 			podSpec, err := resource.GetPodSpec(ctx, kube, kind, name)
 			if err != nil {
 				return err
 			}
 
-			for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
-				if !resource.IsContainedSelected(container, containerRegexp) {
+			for _, container := range resource.Containers(podSpec) {
+				if !resource.IsContainedSelected(container, containerRegexp, containerTypes) {
 					continue
 				}
 
@@ -92,4 +178,541 @@ func initImage() {
 	flags := imageCmd.Flags()
 
 	flags.StringVarP(&container, "container", "c", "", "Filter container's name by regexp, default to all containers")
+	flags.StringSliceVarP(&containerType, "container-type", "", nil, "Filter container's type (init, main, sidecar, ephemeral), default to all types")
+	flags.BoolVarP(&showMatrix, "matrix", "", false, "Print a table of containers by contexts, highlighting images different from the majority and failing on drift")
+	flags.BoolVarP(&showRunning, "running", "", false, "Resolve digests of images run by pods, flagging pods running a digest different from their siblings or from the spec")
+
+	flags.BoolVarP(&allImages, "all", "", false, "List images of every workload in the namespace, instead of a given resource")
+	flags.StringVarP(&imageRegistries, "registry", "", "", "Filter images' registry by regexp, with --all")
+	flags.StringVarP(&imageRepositories, "repository", "", "", "Filter images' repository by regexp, with --all")
+	flags.StringVarP(&imageTags, "tag", "", "", "Filter images' tag by regexp, with --all")
+	flags.StringVarP(&outputFormat, "output", "o", "", "Output format, with --all. One of: (json)")
+
+	flags.BoolVarP(&showHistory, "history", "", false, "List images of every revision of a deployment, statefulset or daemonset, newest first")
+
+	imageCmd.MarkFlagsMutuallyExclusive("all", "matrix", "history")
+	imageCmd.MarkFlagsMutuallyExclusive("all", "running", "history")
+}
+
+// imageHistory lists images of every revision of the resource, per context
+func imageHistory(ctx context.Context, kind, name string) error {
+	histories := make(map[string][]resource.Revision)
+	var mutex sync.Mutex
+
+	clients.Execute(ctx, func(ctx context.Context, kube client.Kube) error {
+		revisions, err := resource.History(ctx, kube, kind, name)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		histories[kube.Name] = revisions
+
+		return nil
+	})
+
+	rows := [][]table.Cell{{
+		table.NewCell("CONTEXT"),
+		table.NewCell("REVISION"),
+		table.NewCell("STATE"),
+		table.NewCell("AGE"),
+		table.NewCell("REPLICAS"),
+		table.NewCell("CONTAINER"),
+		table.NewCell("IMAGE"),
+	}}
+
+	for _, contextName := range clients.Names() {
+		for index, revision := range histories[contextName] {
+			state := table.NewCell("")
+
+			switch index {
+			case 0:
+				state = table.NewCellColor("current", output.Green)
+			case 1:
+				state = table.NewCellColor("rollback", output.Yellow)
+			}
+
+			for _, container := range resource.Containers(revision.Spec) {
+				if !resource.IsContainedSelected(container, containerRegexp, containerTypes) {
+					continue
+				}
+
+				rows = append(rows, []table.Cell{
+					table.NewCellColor(contextName, output.Blue),
+					table.NewCell(strconv.FormatInt(revision.Number, 10)),
+					state,
+					table.NewCell(duration.HumanDuration(time.Since(revision.Created))),
+					table.NewCell(strconv.FormatInt(int64(revision.Replicas), 10)),
+					table.NewCell(container.Name),
+					table.NewCell(container.Image),
+				})
+			}
+		}
+	}
+
+	printTable(rows)
+
+	return nil
+}
+
+// inventoryImages lists the images of every workload of the namespace, per context
+func inventoryImages(ctx context.Context) error {
+	if len(outputFormat) != 0 && outputFormat != "json" {
+		return fmt.Errorf("unhandled output format `%s`", outputFormat)
+	}
+
+	filters, err := newImageFilters(imageRegistries, imageRepositories, imageTags)
+	if err != nil {
+		return err
+	}
+
+	var items []imageItem
+	var mutex sync.Mutex
+
+	clients.Execute(ctx, func(ctx context.Context, kube client.Kube) error {
+		workloads, err := resource.ListWorkloads(ctx, kube)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, workload := range workloads {
+			for _, container := range resource.Containers(workload.Spec) {
+				if !resource.IsContainedSelected(container, containerRegexp, containerTypes) {
+					continue
+				}
+
+				image := resource.ParseImage(container.Image)
+
+				if !filters.match(image) {
+					continue
+				}
+
+				items = append(items, imageItem{
+					Context:    kube.Name,
+					Namespace:  workload.Namespace,
+					Kind:       workload.Kind,
+					Name:       workload.Name,
+					Container:  container.Name,
+					Image:      container.Image,
+					Registry:   image.Registry,
+					Repository: image.Repository,
+					Tag:        image.Tag,
+					Digest:     image.Digest,
+				})
+			}
+		}
+
+		return nil
+	})
+
+	slices.SortFunc(items, func(a, b imageItem) int {
+		return strings.Compare(
+			strings.Join([]string{a.Context, a.Namespace, a.Kind, a.Name, a.Container}, "/"),
+			strings.Join([]string{b.Context, b.Namespace, b.Kind, b.Name, b.Container}, "/"),
+		)
+	})
+
+	if outputFormat == "json" {
+		payload, err := json.MarshalIndent(items, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal: %w", err)
+		}
+
+		output.Std("", "%s", payload)
+
+		return nil
+	}
+
+	rows := [][]table.Cell{{
+		table.NewCell("CONTEXT"),
+		table.NewCell("NAMESPACE"),
+		table.NewCell("KIND"),
+		table.NewCell("NAME"),
+		table.NewCell("CONTAINER"),
+		table.NewCell("IMAGE"),
+	}}
+
+	for _, item := range items {
+		rows = append(rows, []table.Cell{
+			table.NewCellColor(item.Context, output.Blue),
+			table.NewCell(item.Namespace),
+			table.NewCell(item.Kind),
+			table.NewCell(item.Name),
+			table.NewCell(item.Container),
+			table.NewCellColor(item.Image, output.Green),
+		})
+	}
+
+	printTable(rows)
+
+	return nil
+}
+
+// imageFilters are the registry, repository and tag patterns of an image, a missing one matching everything
+type imageFilters [3]*regexp.Regexp
+
+func newImageFilters(registry, repository, tag string) (imageFilters, error) {
+	var filters imageFilters
+
+	for index, pattern := range []string{registry, repository, tag} {
+		if len(pattern) == 0 {
+			continue
+		}
+
+		var err error
+
+		filters[index], err = regexp.Compile(pattern)
+		if err != nil {
+			return filters, fmt.Errorf("compile image filter `%s`: %w", pattern, err)
+		}
+	}
+
+	return filters, nil
+}
+
+func (f imageFilters) match(image resource.Image) bool {
+	return regexpMatch(f[0], image.Registry) && regexpMatch(f[1], image.Repository) && regexpMatch(f[2], image.Tag)
+}
+
+func regexpMatch(pattern *regexp.Regexp, value string) bool {
+	return pattern == nil || pattern.MatchString(value)
+}
+
+// runningImage is a group of pods running the same image's digest for a container
+type runningImage struct {
+	context   string
+	container string
+	digest    string
+	image     string
+	drift     string
+	pods      []string
+}
+
+func runningImages(ctx context.Context, cmd *cobra.Command, kind, name string) error {
+	var images []runningImage
+	var mutex sync.Mutex
+
+	clients.Execute(ctx, func(ctx context.Context, kube client.Kube) error {
+		podSpec, err := resource.GetPodSpec(ctx, kube, kind, name)
+		if err != nil {
+			return err
+		}
+
+		pods, err := resource.ListPods(ctx, kube, kind, name)
+		if err != nil {
+			return err
+		}
+
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		for _, container := range resource.Containers(podSpec) {
+			if resource.IsContainedSelected(container, containerRegexp, containerTypes) {
+				images = append(images, groupRunningImages(kube.Name, container.Container, pods)...)
+			}
+		}
+
+		return nil
+	})
+
+	slices.SortStableFunc(images, func(a, b runningImage) int {
+		return strings.Compare(a.context+"/"+a.container, b.context+"/"+b.container)
+	})
+
+	var drifts uint
+
+	if showMatrix {
+		matrix := newImageMatrix(clients.Names())
+
+		digests := make(map[[2]string][]string)
+		for _, image := range images {
+			key := [2]string{image.container, image.context}
+			if len(image.digest) != 0 && !slices.Contains(digests[key], image.digest) {
+				digests[key] = append(digests[key], image.digest)
+			}
+		}
+
+		for key, values := range digests {
+			value := shortDigest(values[0])
+			if len(values) > 1 {
+				value = fmt.Sprintf("%d digests", len(values))
+			}
+
+			matrix.add(key[0], key[1], value)
+		}
+
+		drifts = matrix.print()
+	} else {
+		drifts = printRunningImages(images)
+	}
+
+	if drifts != 0 {
+		cmd.SilenceUsage = true
+
+		return fmt.Errorf("image drift found in %d container(s)", drifts)
+	}
+
+	return nil
+}
+
+// groupRunningImages groups pods by the digest they run for the container, flagging the ones different from the spec or from the majority
+func groupRunningImages(contextName string, container v1.Container, pods []v1.Pod) []runningImage {
+	spec := resource.ParseImage(container.Image)
+
+	var groups []*runningImage
+	var majority string
+
+	counts := make(map[string]int)
+	byKey := make(map[string]*runningImage)
+
+	for _, pod := range pods {
+		status, ok := resource.ContainerStatus(pod, container.Name)
+		if !ok {
+			continue
+		}
+
+		digest := resource.Digest(status.ImageID)
+		key := digest + " " + status.Image
+
+		group, ok := byKey[key]
+		if !ok {
+			group = &runningImage{
+				context:   contextName,
+				container: container.Name,
+				digest:    digest,
+				image:     status.Image,
+				drift:     specDrift(spec, digest, status.Image),
+			}
+
+			byKey[key] = group
+			groups = append(groups, group)
+		}
+
+		group.pods = append(group.pods, pod.Name)
+
+		if len(digest) != 0 {
+			counts[digest]++
+
+			if counts[digest] > counts[majority] {
+				majority = digest
+			}
+		}
+	}
+
+	output := make([]runningImage, 0, len(groups))
+
+	for _, group := range groups {
+		// a pinned spec being the reference, a pod running it doesn't drift, whatever its siblings run
+		if len(group.drift) == 0 && len(spec.Digest) == 0 && len(group.digest) != 0 && group.digest != majority {
+			group.drift = "differs from siblings"
+		}
+
+		output = append(output, *group)
+	}
+
+	return output
+}
+
+// specDrift checks the running image against the spec, by digest if the spec is pinned, by name and tag otherwise
+func specDrift(spec resource.Image, digest, image string) string {
+	if len(digest) == 0 {
+		return ""
+	}
+
+	if len(spec.Digest) != 0 {
+		if digest != spec.Digest {
+			return "differs from spec"
+		}
+
+		return ""
+	}
+
+	// some runtimes report the image ID instead of the name
+	if strings.HasPrefix(image, "sha256:") {
+		return ""
+	}
+
+	running := resource.ParseImage(image)
+	running.Digest = ""
+
+	if running != spec {
+		return "differs from spec"
+	}
+
+	return ""
+}
+
+func printRunningImages(images []runningImage) uint {
+	rows := [][]table.Cell{{
+		table.NewCell("CONTEXT"),
+		table.NewCell("CONTAINER"),
+		table.NewCell("DIGEST"),
+		table.NewCell("IMAGE"),
+		table.NewCell("PODS"),
+		table.NewCell("DRIFT"),
+	}}
+
+	drifted := make(map[[2]string]bool)
+
+	for _, image := range images {
+		digest := table.NewCellColor(shortDigest(image.digest), output.Green)
+		drift := table.NewCell("")
+
+		switch {
+		case len(image.digest) == 0:
+			digest = table.NewCellColor("<pending>", output.Yellow)
+		case len(image.drift) != 0:
+			digest = table.NewCellColor(shortDigest(image.digest), output.Red)
+			drift = table.NewCellColor(image.drift+": "+strings.Join(image.pods, ","), output.Red)
+			drifted[[2]string{image.context, image.container}] = true
+		}
+
+		rows = append(rows, []table.Cell{
+			table.NewCellColor(image.context, output.Blue),
+			table.NewCell(image.container),
+			digest,
+			table.NewCell(image.image),
+			table.NewCell(strconv.Itoa(len(image.pods))),
+			drift,
+		})
+	}
+
+	printTable(rows)
+
+	return uint(len(drifted))
+}
+
+// shortDigest keeps the algorithm and the 12 first characters of the digest, like Docker does for IDs
+func shortDigest(digest string) string {
+	algorithm, hash, found := strings.Cut(digest, ":")
+	if !found || len(hash) <= 12 {
+		return digest
+	}
+
+	return algorithm + ":" + hash[:12]
+}
+
+// imageMatrix holds the image of every container, per context
+type imageMatrix struct {
+	images     map[string]map[string]string
+	contexts   []string
+	containers []string
+	mutex      sync.Mutex
+}
+
+func newImageMatrix(contexts []string) *imageMatrix {
+	return &imageMatrix{
+		contexts: contexts,
+		images:   make(map[string]map[string]string),
+	}
+}
+
+func (im *imageMatrix) add(container, contextName, image string) {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+
+	images, ok := im.images[container]
+	if !ok {
+		images = make(map[string]string)
+		im.images[container] = images
+		im.containers = append(im.containers, container)
+	}
+
+	images[contextName] = image
+}
+
+// print outputs the matrix and returns the number of containers that don't run the same image everywhere
+func (im *imageMatrix) print() uint {
+	im.mutex.Lock()
+	defer im.mutex.Unlock()
+
+	slices.Sort(im.containers)
+
+	header := []table.Cell{table.NewCell("CONTAINER")}
+	for _, contextName := range im.contexts {
+		if len(contextName) == 0 {
+			contextName = "current"
+		}
+
+		header = append(header, table.NewCellColor(contextName, output.Blue))
+	}
+
+	rows := [][]table.Cell{header}
+
+	var drifts uint
+
+	for _, container := range im.containers {
+		images := im.images[container]
+		drifted := driftedContexts(images, im.contexts)
+
+		row := []table.Cell{table.NewCell(container)}
+
+		for _, contextName := range im.contexts {
+			image, ok := images[contextName]
+
+			switch {
+			case !ok:
+				row = append(row, table.NewCellColor("-", output.Red))
+			case slices.Contains(drifted, contextName):
+				row = append(row, table.NewCellColor(image, output.Red))
+			default:
+				row = append(row, table.NewCellColor(image, output.Green))
+			}
+		}
+
+		if len(drifted) != 0 {
+			drifts++
+		}
+
+		rows = append(rows, row)
+	}
+
+	printTable(rows)
+
+	return drifts
+}
+
+// driftedContexts returns the contexts where the container is missing or doesn't run the majority's image
+func driftedContexts(images map[string]string, contexts []string) []string {
+	majority := majorityValue(images, contexts)
+
+	var drifted []string
+
+	for _, contextName := range contexts {
+		if image, ok := images[contextName]; !ok || image != majority {
+			drifted = append(drifted, contextName)
+		}
+	}
+
+	return drifted
+}
+
+// majorityValue returns the most common value, the value of the first context winning ties
+func majorityValue(values map[string]string, contexts []string) string {
+	counts := make(map[string]int)
+
+	for _, contextName := range contexts {
+		if value, ok := values[contextName]; ok {
+			counts[value]++
+		}
+	}
+
+	var majority string
+
+	for _, contextName := range contexts {
+		if value, ok := values[contextName]; ok && counts[value] > counts[majority] {
+			majority = value
+		}
+	}
+
+	return majority
+}
+
+func printTable(rows [][]table.Cell) {
+	output.Std("", "%s", table.Render(rows))
 }
@@ -0,0 +1,336 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/resource"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMajorityValue(t *testing.T) {
+	t.Parallel()
+
+	contexts := []string{"dev", "staging", "prod", "dr"}
+
+	cases := map[string]struct {
+		values map[string]string
+		want   string
+	}{
+		"empty": {
+			nil,
+			"",
+		},
+		"all equal": {
+			map[string]string{"dev": "api:1", "staging": "api:1", "prod": "api:1", "dr": "api:1"},
+			"api:1",
+		},
+		"majority": {
+			map[string]string{"dev": "api:2", "staging": "api:1", "prod": "api:1", "dr": "api:1"},
+			"api:1",
+		},
+		"tie": {
+			map[string]string{"dev": "api:1", "staging": "api:2", "prod": "api:2", "dr": "api:1"},
+			"api:1",
+		},
+		"missing context": {
+			map[string]string{"staging": "api:2", "prod": "api:1", "dr": "api:1"},
+			"api:1",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := majorityValue(testCase.values, contexts); got != testCase.want {
+				t.Errorf("majorityValue() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestDriftedContexts(t *testing.T) {
+	t.Parallel()
+
+	contexts := []string{"dev", "staging", "prod"}
+
+	cases := map[string]struct {
+		images map[string]string
+		want   []string
+	}{
+		"all equal": {
+			map[string]string{"dev": "api:1", "staging": "api:1", "prod": "api:1"},
+			nil,
+		},
+		"drift": {
+			map[string]string{"dev": "api:2", "staging": "api:1", "prod": "api:1"},
+			[]string{"dev"},
+		},
+		"missing context": {
+			map[string]string{"dev": "api:1", "staging": "api:1"},
+			[]string{"prod"},
+		},
+		"tie": {
+			map[string]string{"dev": "api:1", "staging": "api:2"},
+			[]string{"staging", "prod"},
+		},
+		"all different": {
+			map[string]string{"dev": "api:1", "staging": "api:2", "prod": "api:3"},
+			[]string{"staging", "prod"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := driftedContexts(testCase.images, contexts); !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("driftedContexts() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestImageFilters(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		registry   string
+		repository string
+		tag        string
+	}
+
+	cases := map[string]struct {
+		args  args
+		image string
+		want  bool
+	}{
+		"no filter": {
+			args{},
+			"nginx:1.27",
+			true,
+		},
+		"registry": {
+			args{
+				registry: "^ghcr.io$",
+			},
+			"ghcr.io/vibioh/kmux:1.0.0",
+			true,
+		},
+		"default registry": {
+			args{
+				registry: "^ghcr.io$",
+			},
+			"nginx:1.27",
+			false,
+		},
+		"repository": {
+			args{
+				repository: "^library/",
+			},
+			"nginx:1.27",
+			true,
+		},
+		"tag": {
+			args{
+				tag: "^1\\.",
+			},
+			"nginx:2.0",
+			false,
+		},
+		"missing tag": {
+			args{
+				tag: "latest",
+			},
+			"nginx@sha256:abcdef",
+			false,
+		},
+		"every filter": {
+			args{
+				registry:   "docker.io",
+				repository: "nginx",
+				tag:        "^1\\.27$",
+			},
+			"nginx:1.27",
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			filters, err := newImageFilters(testCase.args.registry, testCase.args.repository, testCase.args.tag)
+			if err != nil {
+				t.Fatalf("newImageFilters() = %s", err)
+			}
+
+			if got := filters.match(resource.ParseImage(testCase.image)); got != testCase.want {
+				t.Errorf("imageFilters.match() = %t, want %t", got, testCase.want)
+			}
+		})
+	}
+
+	if _, err := newImageFilters("(", "", ""); err == nil {
+		t.Error("newImageFilters() = nil, want error")
+	}
+}
+
+func runningPod(name, image, imageID string) v1.Pod {
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{{Name: "api", Image: image, ImageID: imageID}},
+		},
+	}
+}
+
+func TestGroupRunningImages(t *testing.T) {
+	t.Parallel()
+
+	const (
+		first  = "sha256:1111"
+		second = "sha256:2222"
+	)
+
+	// every group is written as `digest image pods drift`
+	cases := map[string]struct {
+		spec string
+		pods []v1.Pod
+		want []string
+	}{
+		"single pod": {
+			"api:1.0",
+			[]v1.Pod{runningPod("api-1", "docker.io/library/api:1.0", "docker.io/library/api@"+first)},
+			[]string{first + " docker.io/library/api:1.0 api-1 "},
+		},
+		"siblings": {
+			"api:1.0",
+			[]v1.Pod{
+				runningPod("api-1", "api:1.0", "api@"+first),
+				runningPod("api-2", "api:1.0", "api@"+second),
+				runningPod("api-3", "api:1.0", "api@"+second),
+			},
+			[]string{first + " api:1.0 api-1 differs from siblings", second + " api:1.0 api-2,api-3 "},
+		},
+		"tie": {
+			"api:1.0",
+			[]v1.Pod{
+				runningPod("api-1", "api:1.0", "api@"+first),
+				runningPod("api-2", "api:1.0", "api@"+second),
+			},
+			[]string{first + " api:1.0 api-1 ", second + " api:1.0 api-2 differs from siblings"},
+		},
+		"pinned spec": {
+			"api@" + second,
+			[]v1.Pod{
+				runningPod("api-1", "api:1.0", "api@"+first),
+				runningPod("api-2", "api:1.0", "api@"+first),
+				runningPod("api-3", "api@"+second, "api@"+second),
+			},
+			[]string{first + " api:1.0 api-1,api-2 differs from spec", second + " api@" + second + " api-3 "},
+		},
+		"runtime image id": {
+			"api:1.0",
+			[]v1.Pod{runningPod("api-1", first, first)},
+			[]string{first + " " + first + " api-1 "},
+		},
+		"not started": {
+			"api:1.0",
+			[]v1.Pod{runningPod("api-1", "api:1.0", ""), {ObjectMeta: metav1.ObjectMeta{Name: "api-2"}}},
+			[]string{" api:1.0 api-1 "},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+
+			for _, group := range groupRunningImages("prod", v1.Container{Name: "api", Image: testCase.spec}, testCase.pods) {
+				got = append(got, strings.Join([]string{group.digest, group.image, strings.Join(group.pods, ","), group.drift}, " "))
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("groupRunningImages() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestSpecDrift(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		spec   string
+		digest string
+		image  string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"no digest": {
+			args{
+				spec:  "api:1.0",
+				image: "api:1.0",
+			},
+			"",
+		},
+		"same tag": {
+			args{
+				spec:   "api:1.0",
+				digest: "sha256:1111",
+				image:  "docker.io/library/api:1.0",
+			},
+			"",
+		},
+		"other tag": {
+			args{
+				spec:   "api:1.0",
+				digest: "sha256:1111",
+				image:  "api:1.1",
+			},
+			"differs from spec",
+		},
+		"pinned": {
+			args{
+				spec:   "api@sha256:1111",
+				digest: "sha256:1111",
+				image:  "api:1.0",
+			},
+			"",
+		},
+		"pinned other digest": {
+			args{
+				spec:   "api:1.0@sha256:1111",
+				digest: "sha256:2222",
+				image:  "api:1.0",
+			},
+			"differs from spec",
+		},
+		"runtime image id": {
+			args{
+				spec:   "api:1.0",
+				digest: "sha256:1111",
+				image:  "sha256:1111",
+			},
+			"",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := specDrift(resource.ParseImage(testCase.args.spec), testCase.args.digest, testCase.args.image); got != testCase.want {
+				t.Errorf("specDrift() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
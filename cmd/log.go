@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"os"
 	"regexp"
 	"slices"
 	"strings"
@@ -12,9 +13,11 @@ import (
 
 	"github.com/ViBiOh/kmux/pkg/log"
 	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/ViBiOh/kmux/pkg/record"
 	"github.com/ViBiOh/kmux/pkg/resource"
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
@@ -24,19 +27,63 @@ var (
 
 	noFollow bool
 
+	maxStreams uint
+	sampleLog  uint
+
 	since          time.Duration
 	labelsSelector map[string]string
 
-	jsonColorKeys []string
+	logFilters    []string
+	invertGrep    bool
+	highlightGrep bool
+	beforeContext uint
+	afterContext  uint
+	aroundContext uint
+
+	logWheres []string
+
+	recordSession string
+
+	logSinks     []string
+	sinkBatch    uint
+	sinkInterval time.Duration
+
+	untilMatches []string
+	untilPods    uint
+	failOns      []string
+	logTimeout   time.Duration
+
+	logFiles []string
+
+	showEvents   bool
+	showStats    bool
+	showPatterns bool
+
+	showTUI bool
 
-	logFilters []string
-	invertGrep bool
+	byRevision      bool
+	compareRevision bool
+
+	dedupLog    bool
+	dedupWindow time.Duration
+	dedupMask   bool
+
+	redactLog      bool
+	showSecrets    bool
+	redactPatterns []string
+
+	multilineLog   bool
+	multilineStart string
+
+	logFormat   string
+	logTemplate string
+	logFields   []string
 
 	logColorFilter *color.Color
 )
 
 var logCmd = &cobra.Command{
-	Use:     "log TYPE NAME",
+	Use:     "log TYPE NAME | TYPE/NAME...",
 	Aliases: []string{"logs"},
 	Short:   "Get logs of a given resource",
 	ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
@@ -53,7 +100,7 @@ var logCmd = &cobra.Command{
 			}, cobra.ShellCompDirectiveNoFileComp
 		}
 
-		if len(args) == 1 {
+		if len(args) == 1 && !strings.Contains(args[0], "/") {
 			lister, err := resource.ListerFor(args[0])
 			if err != nil {
 				return nil, cobra.ShellCompDirectiveError
@@ -70,70 +117,277 @@ var logCmd = &cobra.Command{
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	},
 	RunE: func(cmd *cobra.Command, args []string) error {
-		if !(len(args) == 2 || len(labelsSelector) != 0 || (len(args) == 1 && slices.Contains([]string{"ns", "namespace", "namespaces"}, args[0]))) {
-			return errors.New("either labels or `TYPE NAME` args must be specified")
+		if len(args) == 0 && len(labelsSelector) == 0 {
+			return errors.New("either labels, `TYPE NAME` or `TYPE/NAME...` args must be specified")
 		}
 
-		ctx, cancel := context.WithCancel(cmd.Context())
-		defer cancel()
+		targets, err := resource.ParseTargets(args)
+		if err != nil {
+			return fmt.Errorf("parse targets: %w", err)
+		}
 
-		go func() {
-			waitForEnd(syscall.SIGINT, syscall.SIGTERM)
-			cancel()
-		}()
+		return runLog(cmd, targets, len(clients), func(ctx context.Context, logger log.Logger) error {
+			if len(recordSession) != 0 {
+				recorder, err := record.New(recordSession, "log", clients.Names())
+				if err != nil {
+					return fmt.Errorf("record: %w", err)
+				}
 
-		if len(container) != 0 {
-			var err error
+				defer func() {
+					if closeErr := recorder.Close(); closeErr != nil {
+						output.Err("", "close record: %s", closeErr)
+					}
+				}()
 
-			containerRegexp, err = regexp.Compile(container)
-			if err != nil {
-				return fmt.Errorf("container filter compile: %w", err)
+				logger = logger.WithRecorder(recorder)
 			}
+
+			clients.Execute(ctx, logger.Log)
+
+			return nil
+		})
+	},
+}
+
+// runLog builds the logger from flags, executes it and handles the end of the output
+func runLog(cmd *cobra.Command, targets []resource.Target, contexts int, execute func(context.Context, log.Logger) error) error {
+	if err := bindLogFlags(cmd.Flags()); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(cmd.Context())
+	defer cancel()
+
+	if logTimeout != 0 {
+		ctx, cancel = context.WithTimeout(ctx, logTimeout)
+		defer cancel()
+	}
+
+	go func() {
+		waitForEnd(syscall.SIGINT, syscall.SIGTERM)
+		cancel()
+	}()
+
+	if len(container) != 0 {
+		var err error
+
+		containerRegexp, err = regexp.Compile(container)
+		if err != nil {
+			return fmt.Errorf("container filter compile: %w", err)
+		}
+	}
+
+	var err error
+
+	containerTypes, err = resource.ParseContainerTypes(containerType)
+	if err != nil {
+		return fmt.Errorf("container type: %w", err)
+	}
+
+	logRegexes := make([]*regexp.Regexp, len(logFilters))
+
+	for index, logFilter := range logFilters {
+		var err error
+
+		logRegexes[index], err = regexp.Compile(logFilter)
+		if err != nil {
+			return fmt.Errorf("compile log filter `%s`: %w", logFilter, err)
 		}
+	}
 
-		logRegexes := make([]*regexp.Regexp, len(logFilters))
+	if len(outputFormat) != 0 && outputFormat != log.NDJSONFormat {
+		return fmt.Errorf("unhandled output format `%s`", outputFormat)
+	}
 
-		for index, logFilter := range logFilters {
-			var err error
+	var multilineRegexp *regexp.Regexp
 
-			logRegexes[index], err = regexp.Compile(logFilter)
-			if err != nil {
-				return fmt.Errorf("compile log filter `%s`: %w", logFilter, err)
-			}
+	if len(multilineStart) != 0 {
+		var err error
+
+		multilineRegexp, err = regexp.Compile(multilineStart)
+		if err != nil {
+			return fmt.Errorf("compile multiline start `%s`: %w", multilineStart, err)
 		}
+	}
+
+	fieldFilters := make([]log.FieldFilter, len(logWheres))
+
+	for index, logWhere := range logWheres {
+		var err error
 
-		if grepColor := viper.GetString("grepColor"); len(grepColor) != 0 {
-			logColorFilter = log.ColorFromName(strings.ToLower(grepColor))
+		fieldFilters[index], err = log.ParseFieldFilter(logWhere)
+		if err != nil {
+			return fmt.Errorf("parse field filter `%s`: %w", logWhere, err)
 		}
+	}
+
+	var renderer log.Renderer
 
-		if levelKeys := viper.GetStringSlice("levelKeys"); len(levelKeys) != 0 {
-			jsonColorKeys = append(jsonColorKeys, levelKeys...)
+	if len(logTemplate) != 0 {
+		var err error
+
+		renderer, err = log.NewTemplateRenderer(logTemplate)
+		if err != nil {
+			return fmt.Errorf("log template: %w", err)
 		}
+	} else if len(logFields) != 0 {
+		renderer = log.NewFieldsRenderer(logFields)
+	}
+
+	if grepColor := viper.GetString("grepColor"); len(grepColor) != 0 {
+		logColorFilter = log.ColorFromName(strings.ToLower(grepColor))
+	}
+
+	if len(logFormat) != 0 && !slices.Contains(log.LogFormats, logFormat) {
+		return fmt.Errorf("unhandled log format `%s`", logFormat)
+	}
 
-		if statusCodeKeys := viper.GetStringSlice("statusCodeKeys"); len(statusCodeKeys) != 0 {
-			jsonColorKeys = append(jsonColorKeys, statusCodeKeys...)
+	if aroundContext != 0 {
+		if beforeContext == 0 {
+			beforeContext = aroundContext
 		}
 
-		var kind, name string
-		if len(args) > 1 {
-			kind = args[0]
-			name = args[1]
+		if afterContext == 0 {
+			afterContext = aroundContext
 		}
+	}
 
-		logger := log.NewLogger(kind, name, labelsSelector, since).
-			WithDryRun(dryRun).
-			WithContainerRegexp(containerRegexp).
-			WithNoFollow(noFollow).
-			WithLogRegexes(logRegexes).
-			WithInvertRegexp(invertGrep).
-			WithColorFilter(logColorFilter).
-			WithJsonColorKeys(jsonColorKeys).
-			WithRawOutput(rawOutput)
+	var collector log.Collector
+	var stopCollector func()
+	var tui *log.TUI
+
+	switch {
+	case showStats:
+		stats := log.NewStats()
+		stopCollector = stats.Start(time.Second)
+		collector = stats
+
+	case showPatterns:
+		patterns := log.NewPatterns()
+		stopCollector = patterns.Print
+		collector = patterns
+
+	case compareRevision:
+		comparison := log.NewComparison()
+		stopCollector = comparison.Print
+		collector = comparison
+
+	case showTUI:
+		// grep and level filters are applied by the TUI, for being changed on the fly
+		tui = log.NewTUI(logRegexes, invertGrep, logColorFilter)
+		collector = tui
+		logRegexes, invertGrep, logColorFilter = nil, false, nil
+	}
 
-		clients.Execute(ctx, logger.Log)
+	var trigger *log.Trigger
 
-		return nil
-	},
+	if len(untilMatches) != 0 || len(failOns) != 0 {
+		untilRegexes, err := compileRegexes(untilMatches)
+		if err != nil {
+			return fmt.Errorf("until match: %w", err)
+		}
+
+		failRegexes, err := compileRegexes(failOns)
+		if err != nil {
+			return fmt.Errorf("fail on: %w", err)
+		}
+
+		trigger = log.NewTrigger(untilRegexes, failRegexes, contexts, untilPods, cancel)
+	}
+
+	for _, value := range logSinks {
+		sink, err := output.ParseSink(value, sinkBatch, sinkInterval)
+		if err != nil {
+			return fmt.Errorf("sink: %w", err)
+		}
+
+		output.AddSink(sink)
+	}
+
+	var dedup *log.Dedup
+	if dedupLog || dedupWindow != 0 {
+		dedup = log.NewDedup(dedupWindow, dedupMask)
+	}
+
+	var redactor, secretsRedactor *log.Redactor
+	if !showSecrets {
+		patterns := make([]*regexp.Regexp, 0, len(redactPatterns))
+
+		for _, redactPattern := range append(redactPatterns, viper.GetStringSlice("redactPatterns")...) {
+			pattern, err := regexp.Compile(redactPattern)
+			if err != nil {
+				return fmt.Errorf("compile redact pattern `%s`: %w", redactPattern, err)
+			}
+
+			patterns = append(patterns, pattern)
+		}
+
+		secretsRedactor = log.NewRedactor(patterns)
+
+		if redactLog || !isTerminal(os.Stdout) {
+			redactor = secretsRedactor
+		}
+	}
+
+	logger := log.NewLogger(targets, labelsSelector, since).
+		WithDryRun(dryRun).
+		WithContainerRegexp(containerRegexp).
+		WithContainerTypes(containerTypes).
+		WithNoFollow(noFollow).
+		WithMaxStreams(maxStreams).
+		WithSample(sampleLog).
+		WithMultiline(multilineLog, multilineRegexp).
+		WithLogRegexes(logRegexes).
+		WithInvertRegexp(invertGrep).
+		WithHighlight(highlightGrep).
+		WithGrepContext(beforeContext, afterContext).
+		WithFieldFilters(fieldFilters).
+		WithColorFilter(logColorFilter).
+		WithLevelKeys(viper.GetStringSlice("levelKeys"), viper.IsSet("levelKeys")).
+		WithStatusKeys(viper.GetStringSlice("statusCodeKeys"), viper.IsSet("statusCodeKeys")).
+		WithLogFormat(logFormat).
+		WithRenderer(renderer).
+		WithEvents(showEvents).
+		WithFiles(logFiles).
+		WithCollector(collector).
+		WithDedup(dedup).
+		WithRedactor(redactor).
+		WithRecordRedactor(secretsRedactor).
+		WithTrigger(trigger).
+		WithByRevision(byRevision || compareRevision).
+		WithOutputFormat(outputFormat).
+		WithRawOutput(rawOutput)
+
+	if tui != nil {
+		stopCollector, err = tui.Start(cancel)
+		if err != nil {
+			return fmt.Errorf("start tui: %w", err)
+		}
+	}
+
+	executeErr := execute(ctx, logger)
+
+	if dedup != nil {
+		dedup.Flush()
+	}
+
+	if stopCollector != nil {
+		stopCollector()
+	}
+
+	if executeErr != nil {
+		return executeErr
+	}
+
+	if trigger != nil {
+		if err := trigger.Err(ctx.Err()); err != nil {
+			cmd.SilenceUsage = true
+
+			return err
+		}
+	}
+
+	return nil
 }
 
 func initLog() {
@@ -141,29 +395,100 @@ func initLog() {
 
 	flags.DurationVarP(&since, "since", "s", time.Hour, "Display logs since given duration")
 	flags.StringVarP(&container, "container", "c", "", "Filter container's name by regexp, default to all containers")
+	flags.StringSliceVarP(&containerType, "container-type", "", nil, "Filter container's type (init, main, sidecar, ephemeral), default to all types")
 
 	flags.BoolVarP(&dryRun, "dry-run", "d", false, "Dry-run, print only pods")
-	flags.BoolVarP(&rawOutput, "raw-output", "r", false, "Raw ouput, don't print context or pod prefixes")
 
 	flags.BoolVarP(&noFollow, "no-follow", "", false, "Don't follow logs")
+	flags.UintVarP(&maxStreams, "max-streams", "", 0, "Maximum number of concurrent streams per context, not ready or restarting pods first, then newest")
+	flags.UintVarP(&sampleLog, "sample", "", 0, "Follow only n pods per workload, replacing them when they go away")
+	flags.StringArrayVarP(&logFiles, "file", "f", nil, "Stream given file inside containers with tail, instead of containers' output")
+	flags.BoolVarP(&showEvents, "events", "e", false, "Print Kubernetes events of pods and their owners")
+	flags.StringVarP(&recordSession, "record", "", "", "Record log lines with their source and events into given file, secrets being redacted unless --show-secrets, for a later kmux replay")
 
 	flags.StringToStringVarP(&labelsSelector, "selector", "l", nil, "Labels to filter pods")
 
+	addLogProcessingFlags(logCmd)
+
+	// events are printed as-is, they would overwrite the dashboard or the full-screen view
+	logCmd.MarkFlagsMutuallyExclusive("events", "stats", "patterns", "compare", "tui")
+}
+
+// addLogProcessingFlags adds flags of filtering and rendering log lines, shared by `log` and `replay`
+func addLogProcessingFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
+	flags.BoolVarP(&rawOutput, "raw-output", "r", false, "Raw ouput, don't print context or pod prefixes")
+	flags.StringVarP(&outputFormat, "output", "o", "", "Output format. One of: (ndjson)")
+
+	flags.StringArrayVarP(&logSinks, "sink", "", nil, "Ship log lines to a sink, TYPE=URL with TYPE one of (loki, otlp, syslog), e.g. loki=http://localhost:3100 or syslog=udp://localhost:514")
+	flags.UintVarP(&sinkBatch, "sink-batch", "", 100, "Number of lines sent at once to sinks")
+	flags.DurationVarP(&sinkInterval, "sink-interval", "", time.Second, "Maximum duration between two batches sent to sinks")
+
+	flags.BoolVarP(&dedupLog, "dedup", "", false, "Collapse consecutive repeated lines of a container")
+	flags.DurationVarP(&dedupWindow, "dedup-window", "", 0, "Collapse repeated lines of every container within given duration, implies --dedup")
+	flags.BoolVarP(&dedupMask, "dedup-mask", "", false, "Mask timestamps, IDs and numbers when comparing lines for --dedup")
+
+	flags.BoolVarP(&redactLog, "redact", "", false, "Redact secrets (JWT, Authorization, AWS keys, credit cards, passwords, emails) even when stdout is a terminal")
+	flags.BoolVarP(&showSecrets, "show-secrets", "", false, "Don't redact secrets, done by default when stdout is not a terminal and in recorded sessions")
+	flags.StringArrayVarP(&redactPatterns, "redact-pattern", "", nil, "Additional regexp of secrets to redact (also read from $REDACTPATTERNS)")
+	cmd.MarkFlagsMutuallyExclusive("redact", "show-secrets")
+
+	flags.BoolVarP(&showStats, "stats", "", false, "Display a refreshing dashboard of log statistics instead of log lines")
+	flags.BoolVarP(&showPatterns, "patterns", "", false, "Cluster log messages into patterns, printed ranked by count when logs end")
+	flags.BoolVarP(&byRevision, "by-revision", "", false, "Tag each line with the revision of the pod's ReplicaSet")
+	flags.BoolVarP(&compareRevision, "compare", "", false, "Compare error and warn rates of old and new revisions per context, printed when logs end, implies --by-revision")
+	flags.BoolVarP(&showTUI, "tui", "", false, "Browse logs in a full-screen view with search, pause, scroll-back and live filters, tab focusing a single source")
+	cmd.MarkFlagsMutuallyExclusive("stats", "patterns", "compare", "tui")
+	cmd.MarkFlagsMutuallyExclusive("tui", "output")
+
 	flags.StringArrayVarP(&logFilters, "grep", "g", nil, "Regexp to filter log")
 	flags.BoolVarP(&invertGrep, "invert-match", "v", false, "Invert regexp filter matching")
+	flags.BoolVarP(&highlightGrep, "highlight", "", false, "Highlight regexp matches without filtering log")
+	flags.UintVarP(&beforeContext, "before-context", "B", 0, "Print n lines of leading context before grep's matches")
+	flags.UintVarP(&afterContext, "after-context", "", 0, "Print n lines of trailing context after grep's matches")
+	flags.UintVarP(&aroundContext, "grep-context", "C", 0, "Print n lines of context around grep's matches")
+	flags.StringArrayVarP(&untilMatches, "until-match", "", nil, "Exit successfully once given regexp matched in every context (or --until-pods)")
+	flags.UintVarP(&untilPods, "until-pods", "", 0, "Number of distinct pods that have to match --until-match, instead of every context")
+	flags.StringArrayVarP(&failOns, "fail-on", "", nil, "Exit with an error on the first line matching given regexp")
+	flags.DurationVarP(&logTimeout, "timeout", "", 0, "Stop streaming after given duration, failing if --until-match didn't match")
+	flags.StringArrayVarP(&logWheres, "where", "w", nil, "Filter JSON log by field (key=value, key!=value, key>=500, key~regexp, key, !key), || for OR, repeat for AND")
+
+	flags.BoolVarP(&multilineLog, "multiline", "m", false, "Group multiline events (indented lines, stack traces, goroutine dumps) as one log")
+	flags.StringVarP(&multilineStart, "multiline-start", "", "", "Regexp matching the first line of a multiline event, implies --multiline")
+
+	flags.StringVarP(&logFormat, "log-format", "", "", "Format of logs, overriding pods' annotation. One of: (json, logfmt, text)")
+	flags.StringVarP(&logTemplate, "template", "", "", "Go template for rendering JSON log, e.g. '{{.time}} {{.level}} {{.msg}}'")
+	flags.StringSliceVarP(&logFields, "fields", "", nil, "Fields of JSON log to render, separated by a comma, e.g. time,level,msg")
+	cmd.MarkFlagsMutuallyExclusive("template", "fields")
 
 	flags.String("grepColor", "", "Get logs only above given color (red > yellow > green)")
-	if err := viper.BindPFlag("grepColor", flags.Lookup("grepColor")); err != nil {
-		output.Fatal("bind `grepColor` flag: %s", err)
-	}
-
 	flags.StringSlice("levelKeys", []string{"level", "severity"}, "Keys for level in JSON")
-	if err := viper.BindPFlag("levelKeys", flags.Lookup("levelKeys")); err != nil {
-		output.Fatal("bind `levelKeys` flag: %s", err)
+	flags.StringSlice("statusCodeKeys", []string{"status", "statusCode", "response_code", "http_status", "OriginStatus"}, "Keys for HTTP Status code in JSON")
+}
+
+// bindLogFlags binds flags of the running command to viper, because they are shared by many commands
+func bindLogFlags(flags *pflag.FlagSet) error {
+	for _, name := range []string{"grepColor", "levelKeys", "statusCodeKeys"} {
+		if err := viper.BindPFlag(name, flags.Lookup(name)); err != nil {
+			return fmt.Errorf("bind `%s` flag: %w", name, err)
+		}
 	}
 
-	flags.StringSlice("statusCodeKeys", []string{"status", "statusCode", "response_code", "http_status", "OriginStatus"}, "Keys for HTTP Status code in JSON")
-	if err := viper.BindPFlag("statusCodeKeys", flags.Lookup("statusCodeKeys")); err != nil {
-		output.Fatal("bind `statusCodeKeys` flag: %s", err)
+	return nil
+}
+
+func compileRegexes(values []string) ([]*regexp.Regexp, error) {
+	output := make([]*regexp.Regexp, len(values))
+
+	for index, value := range values {
+		var err error
+
+		output[index], err = regexp.Compile(value)
+		if err != nil {
+			return nil, fmt.Errorf("compile `%s`: %w", value, err)
+		}
 	}
+
+	return output, nil
 }
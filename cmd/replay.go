@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"syscall"
+
+	"github.com/ViBiOh/kmux/pkg/log"
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/ViBiOh/kmux/pkg/record"
+	"github.com/spf13/cobra"
+)
+
+var replaySpeed float64
+
+var replayCmd = &cobra.Command{
+	Use:   "replay FILE",
+	Short: "Replay a session recorded with `log --record` or `watch --record`, without any cluster",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		reader, err := record.Open(args[0])
+		if err != nil {
+			return fmt.Errorf("open session: %w", err)
+		}
+
+		defer func() {
+			if closeErr := reader.Close(); closeErr != nil {
+				output.Err("", "close session: %s", closeErr)
+			}
+		}()
+
+		switch reader.Header.Command {
+		case "log":
+			return runLog(cmd, nil, len(reader.Header.Contexts), func(ctx context.Context, logger log.Logger) error {
+				return logger.Replay(ctx, reader, replaySpeed)
+			})
+
+		case "watch":
+			return replayWatch(cmd.Context(), reader)
+
+		default:
+			return fmt.Errorf("unhandled session of `%s`", reader.Header.Command)
+		}
+	},
+}
+
+func initReplay() {
+	flags := replayCmd.Flags()
+
+	flags.Float64VarP(&replaySpeed, "speed", "", 1, "Speed of replay, 0 for as fast as possible")
+
+	addLogProcessingFlags(replayCmd)
+	addWatchDisplayFlags(replayCmd)
+
+	flags.Lookup("output").Usage = "Output format. One of: (ndjson) for log session, (wide) for watch session"
+}
+
+func replayWatch(ctx context.Context, reader *record.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go func() {
+		waitForEnd(syscall.SIGINT, syscall.SIGTERM)
+		cancel()
+	}()
+
+	watchTable := initWatchTable(reader.Header.Contexts)
+
+	return reader.Replay(ctx, replaySpeed, func(entry record.Entry) {
+		if entry.Type == record.PodType && entry.Object != nil {
+			outputWatch(watchTable, entry.Context, *entry.Object)
+		}
+	})
+}
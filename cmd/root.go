@@ -24,13 +24,16 @@ var (
 
 	container       string
 	containerRegexp *regexp.Regexp
+
+	containerType  []string
+	containerTypes []resource.ContainerType
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "kmux",
 	Short: "Multiplexing kubectl common tasks across clusters",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) (err error) {
-		if cmd.Name() == "version" {
+		if cmd.Name() == "version" || cmd.Name() == "replay" {
 			return
 		}
 
@@ -166,6 +169,9 @@ func init() {
 
 	initLog()
 	rootCmd.AddCommand(logCmd)
+
+	initReplay()
+	rootCmd.AddCommand(replayCmd)
 }
 
 func completeContext(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
@@ -214,6 +220,10 @@ func contains(arr []string, value string) bool {
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
+		// post run is not called on error, printer has to be flushed for not losing the last lines
+		output.Close()
+		<-output.Done()
+
 		output.Fatal("%s", err)
 	}
 }
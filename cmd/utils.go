@@ -14,3 +14,12 @@ func waitForEnd(signals ...os.Signal) {
 
 	<-signalsChan
 }
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
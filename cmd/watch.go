@@ -11,6 +11,7 @@ import (
 
 	"github.com/ViBiOh/kmux/pkg/client"
 	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/ViBiOh/kmux/pkg/record"
 	"github.com/ViBiOh/kmux/pkg/resource"
 	"github.com/ViBiOh/kmux/pkg/sha"
 	"github.com/ViBiOh/kmux/pkg/table"
@@ -33,6 +34,7 @@ var (
 	showLabels      bool
 	showAnnotations bool
 	labelColumns    []string
+	watchRecorder   *record.Recorder
 )
 
 func initWatch() {
@@ -40,6 +42,14 @@ func initWatch() {
 
 	flags.StringVarP(&outputFormat, "output", "o", "", "Output format. One of: (wide)")
 	flags.StringToStringVarP(&labelsSelector, "selector", "l", nil, "Labels to filter pods")
+	flags.StringVarP(&recordSession, "record", "", "", "Record pods' states into given file, for a later kmux replay")
+	addWatchDisplayFlags(watchCmd)
+}
+
+// addWatchDisplayFlags adds flags of rendering pods, shared by `watch` and `replay`
+func addWatchDisplayFlags(cmd *cobra.Command) {
+	flags := cmd.Flags()
+
 	flags.BoolVarP(&showLabels, "show-labels", "", false, "Show all labels as the last column")
 	flags.BoolVarP(&showAnnotations, "show-annotations", "", false, "Show all annotations as the last column (after labels if both asked)")
 	flags.StringSliceVarP(&labelColumns, "label-columns", "L", nil, "Labels that are going to be presented as columns")
@@ -48,7 +58,7 @@ func initWatch() {
 var watchCmd = &cobra.Command{
 	Use:   "watch",
 	Short: "Get all pods in the namespace",
-	Run: func(cmd *cobra.Command, args []string) {
+	RunE: func(cmd *cobra.Command, args []string) error {
 		ctx, cancel := context.WithCancel(cmd.Context())
 		defer cancel()
 
@@ -57,7 +67,22 @@ var watchCmd = &cobra.Command{
 			cancel()
 		}()
 
-		watchTable := initWatchTable()
+		if len(recordSession) != 0 {
+			var err error
+
+			watchRecorder, err = record.New(recordSession, "watch", clients.Names())
+			if err != nil {
+				return fmt.Errorf("record: %w", err)
+			}
+
+			defer func() {
+				if err := watchRecorder.Close(); err != nil {
+					output.Err("", "close record: %s", err)
+				}
+			}()
+		}
+
+		watchTable := initWatchTable(clients.Names())
 		initialsPodsHash := displayInitialPods(ctx, watchTable)
 
 		clients.Execute(ctx, func(ctx context.Context, kube client.Kube) error {
@@ -83,10 +108,12 @@ var watchCmd = &cobra.Command{
 
 			return nil
 		})
+
+		return nil
 	},
 }
 
-func initWatchTable() *table.Table {
+func initWatchTable(contexts []string) *table.Table {
 	defaultWidths := []uint64{
 		45, 5, 9, 6, 14,
 	}
@@ -103,8 +130,8 @@ func initWatchTable() *table.Table {
 		content = append([]table.Cell{table.NewCell("NAMESPACE")}, content...)
 	}
 
-	if len(clients) > 0 && len(clients[0].Name) != 0 {
-		defaultWidths = append([]uint64{uint64(len(clients[0].Name))}, defaultWidths...)
+	if len(contexts) > 0 && len(contexts[0]) != 0 {
+		defaultWidths = append([]uint64{uint64(len(contexts[0]))}, defaultWidths...)
 		content = append([]table.Cell{table.NewCell("CONTEXT")}, content...)
 	}
 
@@ -199,7 +226,40 @@ func (a PodByAge) Less(i, j int) bool {
 	return a[i].Status.StartTime.Before(a[j].Status.StartTime)
 }
 
+// recordedPod removes values of environment variables, and the last applied configuration holding them, from the pod
+// being recorded, sessions being meant to be shared
+func recordedPod(pod v1.Pod) v1.Pod {
+	recorded := *pod.DeepCopy()
+
+	delete(recorded.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+
+	clearValues := func(env []v1.EnvVar) {
+		for index := range env {
+			env[index].Value = ""
+		}
+	}
+
+	for index := range recorded.Spec.InitContainers {
+		clearValues(recorded.Spec.InitContainers[index].Env)
+	}
+
+	for index := range recorded.Spec.Containers {
+		clearValues(recorded.Spec.Containers[index].Env)
+	}
+
+	for index := range recorded.Spec.EphemeralContainers {
+		clearValues(recorded.Spec.EphemeralContainers[index].Env)
+	}
+
+	return recorded
+}
+
 func outputWatch(watchTable *table.Table, contextName string, pod v1.Pod) {
+	if watchRecorder != nil {
+		recorded := recordedPod(pod)
+		watchRecorder.Record(record.Entry{Type: record.PodType, Context: contextName, Object: &recorded})
+	}
+
 	var content []table.Cell
 
 	if len(contextName) != 0 {
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRecordedPod(t *testing.T) {
+	t.Parallel()
+
+	secretRef := &v1.EnvVarSource{SecretKeyRef: &v1.SecretKeySelector{Key: "password"}}
+
+	pod := v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "api-1",
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": `{"env":[{"name":"TOKEN","value":"s3cr3t"}]}`,
+				"team": "platform",
+			},
+		},
+		Spec: v1.PodSpec{
+			InitContainers: []v1.Container{{Name: "migrate", Env: []v1.EnvVar{{Name: "DSN", Value: "postgres://admin:hunter2@db"}}}},
+			Containers:     []v1.Container{{Name: "api", Env: []v1.EnvVar{{Name: "TOKEN", Value: "s3cr3t"}, {Name: "PASSWORD", ValueFrom: secretRef}}}},
+			EphemeralContainers: []v1.EphemeralContainer{
+				{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debugger", Env: []v1.EnvVar{{Name: "KEY", Value: "abc"}}}},
+			},
+		},
+	}
+
+	got := recordedPod(pod)
+
+	want := *pod.DeepCopy()
+	want.Annotations = map[string]string{"team": "platform"}
+	want.Spec.InitContainers[0].Env[0].Value = ""
+	want.Spec.Containers[0].Env[0].Value = ""
+	want.Spec.EphemeralContainers[0].Env[0].Value = ""
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("recordedPod() = %+v, want %+v", got.Spec, want.Spec)
+	}
+
+	if pod.Spec.Containers[0].Env[0].Value != "s3cr3t" {
+		t.Error("recordedPod() modified the given pod")
+	}
+}
@@ -31,6 +31,17 @@ type Action func(context.Context, Kube) error
 
 type Array []Kube
 
+// Names returns the context's name of every client
+func (a Array) Names() []string {
+	names := make([]string, len(a))
+
+	for index, client := range a {
+		names[index] = client.Name
+	}
+
+	return names
+}
+
 func (a Array) Execute(ctx context.Context, action Action) {
 	parallel := concurrent.NewSimple()
 
@@ -43,6 +43,7 @@ func (ev envValue) String() string {
 
 type EnvGetter struct {
 	containerRegexp *regexp.Regexp
+	containerTypes  []resource.ContainerType
 	kind            string
 	name            string
 }
@@ -60,6 +61,12 @@ func (eg EnvGetter) WithContainerRegexp(containerRegexp *regexp.Regexp) EnvGette
 	return eg
 }
 
+func (eg EnvGetter) WithContainerTypes(containerTypes []resource.ContainerType) EnvGetter {
+	eg.containerTypes = containerTypes
+
+	return eg
+}
+
 func (eg EnvGetter) Get(ctx context.Context, kube client.Kube) error {
 	podSpec, err := resource.GetPodSpec(ctx, kube, eg.kind, eg.name)
 	if err != nil {
@@ -86,12 +93,12 @@ func (eg EnvGetter) Get(ctx context.Context, kube client.Kube) error {
 
 	var containers []v1.Container
 
-	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
-		if !resource.IsContainedSelected(container, eg.containerRegexp) {
+	for _, container := range resource.Containers(podSpec) {
+		if !resource.IsContainedSelected(container, eg.containerRegexp, eg.containerTypes) {
 			continue
 		}
 
-		containers = append(containers, container)
+		containers = append(containers, container.Container)
 	}
 
 	for _, container := range containers {
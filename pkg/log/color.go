@@ -23,6 +23,14 @@ var colorRanks = map[*color.Color]uint{
 	output.Green:  3,
 }
 
+// colorSeverities names the colors of the level filter, a line's severity being derived from its level or status
+var colorSeverities = map[*color.Color]string{
+	output.Red:    "error",
+	output.Yellow: "warn",
+	output.White:  "info",
+	output.Green:  "debug",
+}
+
 func ColorFromName(name string) *color.Color {
 	found, ok := colorNames[name]
 	if ok {
@@ -32,6 +40,14 @@ func ColorFromName(name string) *color.Color {
 	return nil
 }
 
+func severityOf(outputter *color.Color) string {
+	if severity, ok := colorSeverities[outputter]; ok {
+		return severity
+	}
+
+	return colorSeverities[output.White]
+}
+
 func colorIsGreater(first, second *color.Color) bool {
 	if first == nil {
 		return true
@@ -44,23 +60,55 @@ func colorIsGreater(first, second *color.Color) bool {
 	return colorRanks[first] > colorRanks[second]
 }
 
+// ColorOfJSON returns the color of the level or status found at given keys, dotted keys (e.g. `log.level`) being resolved in nested objects
 func ColorOfJSON(content string, keys ...string) *color.Color {
+	return colorOfValue(valueOfJSON(content, keys...))
+}
+
+// valueOfJSON returns the level or status found at given keys, nil if none
+func valueOfJSON(content string, keys ...string) any {
 	if !strings.HasPrefix(content, "{") || len(keys) == 0 {
-		return output.White
+		return nil
 	}
 
-	decoder := json.NewDecoder(strings.NewReader(content))
+	var topKeys, nestedKeys []string
 
-	if err := moveDecoderToKey(decoder, keys...); err != nil {
-		return output.White
+	for _, key := range keys {
+		if strings.Contains(key, ".") {
+			nestedKeys = append(nestedKeys, key)
+		} else {
+			topKeys = append(topKeys, key)
+		}
 	}
 
-	token, err := decoder.Token()
-	if err != nil {
-		return output.White
+	if len(topKeys) != 0 {
+		decoder := json.NewDecoder(strings.NewReader(content))
+
+		if err := moveDecoderToKey(decoder, topKeys...); err == nil {
+			token, err := decoder.Token()
+			if err != nil {
+				return nil
+			}
+
+			return token
+		}
 	}
 
-	switch value := token.(type) {
+	if len(nestedKeys) != 0 {
+		fields, _ := parseFields(content)
+
+		for _, key := range nestedKeys {
+			if value, ok := lookupField(fields, key); ok {
+				return value
+			}
+		}
+	}
+
+	return nil
+}
+
+func colorOfValue(value any) *color.Color {
+	switch value := value.(type) {
 	case string:
 		switch strings.ToLower(value) {
 		case "error", "critical", "fatal":
@@ -90,6 +138,36 @@ func ColorOfJSON(content string, keys ...string) *color.Color {
 	}
 }
 
+// severityOfValue returns the severity of a level or of a status, a redirection being informative
+func severityOfValue(value any) string {
+	switch value := value.(type) {
+	case string:
+		switch strings.ToLower(value) {
+		case "error", "critical", "fatal":
+			return "error"
+		case "warn", "warning":
+			return "warn"
+		case "trace", "debug":
+			return "debug"
+		default:
+			return "info"
+		}
+
+	case float64:
+		switch {
+		case value >= http.StatusInternalServerError:
+			return "error"
+		case value >= http.StatusBadRequest:
+			return "warn"
+		default:
+			return "info"
+		}
+
+	default:
+		return "info"
+	}
+}
+
 func moveDecoderToKey(decoder *json.Decoder, keys ...string) error {
 	var (
 		token  json.Token
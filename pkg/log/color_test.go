@@ -0,0 +1,84 @@
+package log
+
+import "testing"
+
+func TestSeverityOfJSON(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		content string
+		keys    []string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"text": {
+			args{
+				content: "started",
+				keys:    []string{"level"},
+			},
+			"info",
+		},
+		"level": {
+			args{
+				content: `{"level":"ERROR","msg":"failed"}`,
+				keys:    []string{"level"},
+			},
+			"error",
+		},
+		"debug level": {
+			args{
+				content: `{"level":"trace"}`,
+				keys:    []string{"level"},
+			},
+			"debug",
+		},
+		"nested level": {
+			args{
+				content: `{"log":{"level":"warning"}}`,
+				keys:    []string{"log.level"},
+			},
+			"warn",
+		},
+		"server error": {
+			args{
+				content: `{"status":503}`,
+				keys:    []string{"status"},
+			},
+			"error",
+		},
+		"client error": {
+			args{
+				content: `{"status":404}`,
+				keys:    []string{"status"},
+			},
+			"warn",
+		},
+		"redirection": {
+			args{
+				content: `{"status":302}`,
+				keys:    []string{"status"},
+			},
+			"info",
+		},
+		"missing key": {
+			args{
+				content: `{"msg":"started"}`,
+				keys:    []string{"level"},
+			},
+			"info",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := severityOfValue(valueOfJSON(testCase.args.content, testCase.args.keys...)); got != testCase.want {
+				t.Errorf("severityOfValue() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
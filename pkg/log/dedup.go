@@ -0,0 +1,208 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+)
+
+const maxDedupEntries = 10000
+
+type repeatedPrinter func(count uint, detail string)
+
+type dedupEntry struct {
+	printer repeatedPrinter
+	sources map[string]uint
+	since   time.Time
+	owner   string
+	count   uint
+}
+
+// Dedup collapses repeated log lines, consecutive ones of a stream and, with a window, the ones of every stream
+type Dedup struct {
+	entries map[string]*dedupEntry
+	done    chan struct{}
+	window  time.Duration
+	stop    sync.Once
+	mutex   sync.Mutex
+	mask    bool
+}
+
+// NewDedup creates a deduplicator, expiring windows in the background, until flushed, if window is set
+func NewDedup(window time.Duration, mask bool) *Dedup {
+	dedup := &Dedup{
+		entries: make(map[string]*dedupEntry),
+		done:    make(chan struct{}),
+		window:  window,
+		mask:    mask,
+	}
+
+	if window > 0 {
+		go dedup.expireEvery(window)
+	}
+
+	return dedup
+}
+
+// expireEvery prints counts of expired windows, without waiting for a repeat
+func (d *Dedup) expireEvery(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.done:
+			return
+
+		case now := <-ticker.C:
+			d.mutex.Lock()
+			d.expire(now)
+			d.mutex.Unlock()
+		}
+	}
+}
+
+func (d *Dedup) key(text string) string {
+	if d.mask {
+		return maskText(text)
+	}
+
+	return text
+}
+
+// seen returns true if the line has to be printed, false if it's a duplicate within the window
+func (d *Dedup) seen(key, sourceName string, printer repeatedPrinter) bool {
+	if d.window == 0 {
+		return true
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	now := time.Now()
+
+	entry, ok := d.entries[key]
+	if ok && now.Sub(entry.since) < d.window {
+		entry.sources[sourceName]++
+		entry.count++
+
+		return false
+	}
+
+	if ok {
+		entry.print(d.window)
+	}
+
+	if len(d.entries) >= maxDedupEntries {
+		d.expire(now)
+		d.evictOldest(len(d.entries) - maxDedupEntries*9/10)
+	}
+
+	d.entries[key] = &dedupEntry{
+		printer: printer,
+		sources: make(map[string]uint),
+		since:   now,
+		owner:   sourceName,
+	}
+
+	return true
+}
+
+func (d *Dedup) expire(now time.Time) {
+	for key, entry := range d.entries {
+		if now.Sub(entry.since) >= d.window {
+			entry.print(d.window)
+			delete(d.entries, key)
+		}
+	}
+}
+
+// evictOldest prints and removes the given number of entries, the oldest first, for bounding memory
+func (d *Dedup) evictOldest(count int) {
+	if count <= 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(d.entries))
+	for key := range d.entries {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return d.entries[keys[i]].since.Before(d.entries[keys[j]].since)
+	})
+
+	for _, key := range keys[:min(count, len(keys))] {
+		d.entries[key].print(d.window)
+		delete(d.entries, key)
+	}
+}
+
+// flushSource prints the count of duplicates of lines printed by a source, its stream having ended
+func (d *Dedup) flushSource(sourceName string) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for key, entry := range d.entries {
+		if entry.owner == sourceName {
+			entry.print(d.window)
+			delete(d.entries, key)
+		}
+	}
+}
+
+// Flush prints the count of every duplicate not yet printed and stops expiring windows
+func (d *Dedup) Flush() {
+	d.stop.Do(func() {
+		close(d.done)
+	})
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for key, entry := range d.entries {
+		entry.print(d.window)
+		delete(d.entries, key)
+	}
+}
+
+func (de *dedupEntry) print(window time.Duration) {
+	if de.count == 0 {
+		return
+	}
+
+	names := make([]string, 0, len(de.sources))
+	for name := range de.sources {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for index, name := range names {
+		names[index] = fmt.Sprintf("%s: %d", name, de.sources[name])
+	}
+
+	de.printer(de.count, fmt.Sprintf("within %s, %s", window, strings.Join(names, ", ")))
+	de.count = 0
+}
+
+func (l Logger) repeatedPrinter(outputter output.Outputter, source source) repeatedPrinter {
+	return func(count uint, detail string) {
+		if l.outputFormat == NDJSONFormat {
+			output.Std("", "%s", source.envelope("", "info", fmt.Sprintf(`{"repeated":%d}`, count)))
+
+			return
+		}
+
+		message := fmt.Sprintf("(repeated %d times)", count)
+		if len(detail) != 0 {
+			message = fmt.Sprintf("(repeated %d times %s)", count, detail)
+		}
+
+		outputter.Std("%s", output.Cyan.Sprint(message))
+	}
+}
@@ -0,0 +1,185 @@
+package log
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+)
+
+func TestDedupSeen(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		window time.Duration
+		mask   bool
+		lines  []string
+	}
+
+	cases := map[string]struct {
+		args      args
+		want      uint
+		wantCount uint
+	}{
+		"no window": {
+			args{
+				lines: []string{"hello", "hello"},
+			},
+			2,
+			0,
+		},
+		"window": {
+			args{
+				window: time.Minute,
+				lines:  []string{"hello", "world", "hello", "hello"},
+			},
+			2,
+			2,
+		},
+		"mask": {
+			args{
+				window: time.Minute,
+				mask:   true,
+				lines:  []string{"2024-01-01T10:00:00Z request 1 done", "2024-01-01T10:00:01Z request 2 done"},
+			},
+			1,
+			1,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			dedup := NewDedup(testCase.args.window, testCase.args.mask)
+
+			var got, gotCount uint
+			printer := func(count uint, _ string) {
+				gotCount += count
+			}
+
+			for _, line := range testCase.args.lines {
+				if dedup.seen(dedup.key(line), "pod/container", printer) {
+					got++
+				}
+			}
+
+			dedup.Flush()
+
+			if got != testCase.want || gotCount != testCase.wantCount {
+				t.Errorf("seen() = (%d, %d), want (%d, %d)", got, gotCount, testCase.want, testCase.wantCount)
+			}
+		})
+	}
+}
+
+func TestDedupFlushSource(t *testing.T) {
+	t.Parallel()
+
+	dedup := NewDedup(time.Minute, false)
+	defer dedup.Flush()
+
+	got := make(map[string]uint)
+	printerOf := func(name string) repeatedPrinter {
+		return func(count uint, _ string) {
+			got[name] += count
+		}
+	}
+
+	dedup.seen("hello", "api", printerOf("api"))
+	dedup.seen("world", "worker", printerOf("worker"))
+	dedup.seen("hello", "worker", printerOf("worker"))
+	dedup.seen("world", "api", printerOf("api"))
+
+	dedup.flushSource("api")
+
+	if got["api"] != 1 || got["worker"] != 0 {
+		t.Errorf("flushSource() = %v, want %v", got, map[string]uint{"api": 1})
+	}
+
+	if !dedup.seen("hello", "worker", printerOf("worker")) {
+		t.Error("seen() = false after its source ended, want true")
+	}
+}
+
+func TestDedupExpireEvery(t *testing.T) {
+	t.Parallel()
+
+	dedup := NewDedup(time.Millisecond*50, false)
+	defer dedup.Flush()
+
+	printed := make(chan uint, 1)
+	printer := func(count uint, _ string) {
+		printed <- count
+	}
+
+	dedup.seen("hello", "api", printer)
+	dedup.seen("hello", "api", printer)
+
+	select {
+	case got := <-printed:
+		if got != 1 {
+			t.Errorf("expireEvery() = %d, want %d", got, 1)
+		}
+
+	case <-time.After(time.Second):
+		t.Error("expireEvery() didn't print the expired window")
+	}
+}
+
+// TestDedupWindowRepeats is not parallel, printed lines being captured
+func TestDedupWindowRepeats(t *testing.T) {
+	dedup := NewDedup(time.Hour, false)
+
+	var count uint
+	dedup.seen("x", "prod/api-1/api", func(repeated uint, _ string) {
+		count += repeated
+	})
+
+	got := captureLines(func() {
+		Logger{}.
+			WithDedup(dedup).
+			WithRawOutput(true).
+			outputLog(strings.NewReader("x\nx\nx\ny\ny"), output.NewOutputter(""), source{Context: "prod", Pod: "api-2", Container: "api"})
+	})
+
+	if want := []string{"y", "(repeated 1 times)"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("outputEvents() = %q, want %q", got, want)
+	}
+
+	dedup.Flush()
+
+	if count != 3 {
+		t.Errorf("seen() = %d, want %d", count, 3)
+	}
+}
+
+func TestDedupMaxEntries(t *testing.T) {
+	t.Parallel()
+
+	dedup := NewDedup(time.Hour, false)
+	defer dedup.Flush()
+
+	var evicted uint
+	printer := func(repeated uint, _ string) {
+		evicted += repeated
+	}
+
+	dedup.seen("oldest", "api", printer)
+	dedup.seen("oldest", "api", printer)
+
+	for index := range maxDedupEntries {
+		dedup.seen(strconv.Itoa(index), "api", printer)
+	}
+
+	if got := len(dedup.entries); got > maxDedupEntries {
+		t.Errorf("seen() = %d entries, want at most %d", got, maxDedupEntries)
+	}
+
+	if _, ok := dedup.entries["oldest"]; ok || evicted != 1 {
+		t.Errorf("seen() kept the oldest entry or didn't print it, printed %d", evicted)
+	}
+}
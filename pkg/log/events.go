@@ -0,0 +1,225 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/fatih/color"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+var containerFieldPath = regexp.MustCompile(`\{(.*)\}`)
+
+// eventTracker holds the UIDs of streamed pods and their owners, for filtering events
+type eventTracker struct {
+	uids sync.Map
+}
+
+// involves returns true if the event is about a streamed pod or one of its owners
+func (t *eventTracker) involves(event v1.Event) bool {
+	_, ok := t.uids.Load(event.InvolvedObject.UID)
+
+	return ok
+}
+
+func (l Logger) watchEvents(ctx context.Context, kube client.Kube, namespace string, tracker *eventTracker) (watch.Interface, error) {
+	// listing for getting the current resource version, past events are listed when tracking objects
+	events, err := kube.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("list events: %w", err)
+	}
+
+	watcher, err := kube.CoreV1().Events(namespace).Watch(ctx, metav1.ListOptions{ResourceVersion: events.ResourceVersion})
+	if err != nil {
+		return nil, fmt.Errorf("watch events: %w", err)
+	}
+
+	go func() {
+		for event := range watcher.ResultChan() {
+			if event.Type != watch.Added && event.Type != watch.Modified {
+				continue
+			}
+
+			item, ok := event.Object.(*v1.Event)
+			if !ok {
+				continue
+			}
+
+			if tracker.involves(*item) {
+				l.printEvent(kube, *item)
+			}
+		}
+	}()
+
+	return watcher, nil
+}
+
+func (l Logger) trackPod(ctx context.Context, kube client.Kube, tracker *eventTracker, pod v1.Pod) {
+	l.trackObject(ctx, kube, tracker, pod.Namespace, pod.UID)
+
+	for _, owner := range pod.OwnerReferences {
+		if !l.trackObject(ctx, kube, tracker, pod.Namespace, owner.UID) {
+			continue
+		}
+
+		var owners []metav1.OwnerReference
+
+		switch owner.Kind {
+		case "ReplicaSet":
+			replicaSet, err := kube.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+			if err != nil {
+				kube.Warn("get replicaset `%s`: %s", owner.Name, err)
+				continue
+			}
+
+			owners = replicaSet.OwnerReferences
+
+		case "Job":
+			job, err := kube.BatchV1().Jobs(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+			if err != nil {
+				kube.Warn("get job `%s`: %s", owner.Name, err)
+				continue
+			}
+
+			owners = job.OwnerReferences
+		}
+
+		for _, parent := range owners {
+			l.trackObject(ctx, kube, tracker, pod.Namespace, parent.UID)
+		}
+	}
+}
+
+// trackObject adds the object to the tracked ones and prints its past events, it returns false if already tracked
+func (l Logger) trackObject(ctx context.Context, kube client.Kube, tracker *eventTracker, namespace string, uid types.UID) bool {
+	if _, loaded := tracker.uids.LoadOrStore(uid, struct{}{}); loaded {
+		return false
+	}
+
+	events, err := kube.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.uid=%s", uid),
+	})
+	if err != nil {
+		kube.Warn("list events: %s", err)
+		return true
+	}
+
+	for _, event := range recentEvents(events.Items, time.Duration(l.since)*time.Second, time.Now()) {
+		l.printEvent(kube, event)
+	}
+
+	return true
+}
+
+// recentEvents keeps events that happened since the given duration, in chronological order
+func recentEvents(events []v1.Event, since time.Duration, now time.Time) []v1.Event {
+	var output []v1.Event
+
+	for _, event := range events {
+		if now.Sub(eventTime(event)) <= since {
+			output = append(output, event)
+		}
+	}
+
+	sort.SliceStable(output, func(i, j int) bool {
+		return eventTime(output[i]).Before(eventTime(output[j]))
+	})
+
+	return output
+}
+
+func (l Logger) printEvent(kube client.Kube, event v1.Event) {
+	if l.recorder != nil {
+		l.recordEvent(kube, event)
+	}
+
+	object, container := eventObject(event)
+
+	message := strings.TrimSpace(event.Message)
+	if l.redactor != nil {
+		message = l.redactor.Redact(message)
+	}
+
+	eventColor := eventColorOf(event)
+
+	if l.outputFormat == NDJSONFormat {
+		payload, err := json.Marshal(map[string]any{
+			"kind":    event.InvolvedObject.Kind,
+			"name":    event.InvolvedObject.Name,
+			"type":    event.Type,
+			"reason":  event.Reason,
+			"message": message,
+			"count":   event.Count,
+		})
+		if err != nil {
+			kube.Err("marshal event: %s", err)
+			return
+		}
+
+		eventSource := source{
+			Context:   kube.Name,
+			Namespace: event.InvolvedObject.Namespace,
+			Container: container,
+		}
+
+		if event.InvolvedObject.Kind == "Pod" {
+			eventSource.Pod = event.InvolvedObject.Name
+		}
+
+		output.Std("", "%s", eventSource.envelope(eventTime(event).Format(time.RFC3339Nano), severityOf(eventColor), string(payload)))
+
+		return
+	}
+
+	kube.Child(l.rawOutput, output.Green.Sprintf("[%s]", object)).Std("%s", eventColor.Sprintf("%s", formatEvent(event, message)))
+}
+
+// eventObject returns the name of the involved object for prefixing the event, and its container if any
+func eventObject(event v1.Event) (string, string) {
+	if event.InvolvedObject.Kind != "Pod" {
+		return strings.ToLower(event.InvolvedObject.Kind) + "/" + event.InvolvedObject.Name, ""
+	}
+
+	matches := containerFieldPath.FindStringSubmatch(event.InvolvedObject.FieldPath)
+	if len(matches) < 2 {
+		return event.InvolvedObject.Name, ""
+	}
+
+	return event.InvolvedObject.Name + "/" + matches[1], matches[1]
+}
+
+func eventColorOf(event v1.Event) *color.Color {
+	if event.Type == v1.EventTypeWarning {
+		return output.Yellow
+	}
+
+	return output.Magenta
+}
+
+func formatEvent(event v1.Event, message string) string {
+	return fmt.Sprintf("%s %s: %s", event.Type, event.Reason, message)
+}
+
+func eventTime(event v1.Event) time.Time {
+	switch {
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
@@ -0,0 +1,173 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/fatih/color"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestRecentEvents(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	eventAt := func(reason string, ago time.Duration) v1.Event {
+		return v1.Event{Reason: reason, LastTimestamp: metav1.NewTime(now.Add(-ago))}
+	}
+
+	cases := map[string]struct {
+		events []v1.Event
+		since  time.Duration
+		want   []string
+	}{
+		"empty": {
+			nil,
+			time.Hour,
+			nil,
+		},
+		"too old": {
+			[]v1.Event{eventAt("Scheduled", 2*time.Hour), eventAt("Pulled", 30*time.Minute)},
+			time.Hour,
+			[]string{"Pulled"},
+		},
+		"chronological": {
+			[]v1.Event{eventAt("Started", time.Minute), eventAt("Scheduled", 3*time.Minute), eventAt("Pulled", 2*time.Minute)},
+			time.Hour,
+			[]string{"Scheduled", "Pulled", "Started"},
+		},
+		"event time first": {
+			[]v1.Event{
+				{Reason: "Killing", EventTime: metav1.NewMicroTime(now.Add(-time.Minute)), LastTimestamp: metav1.NewTime(now.Add(-2 * time.Hour))},
+			},
+			time.Hour,
+			[]string{"Killing"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, event := range recentEvents(testCase.events, testCase.since, now) {
+				got = append(got, event.Reason)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("recentEvents() = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestEventTrackerInvolves(t *testing.T) {
+	t.Parallel()
+
+	tracker := &eventTracker{}
+	tracker.uids.Store(types.UID("pod-uid"), struct{}{})
+	tracker.uids.Store(types.UID("replicaset-uid"), struct{}{})
+
+	cases := map[string]struct {
+		uid  string
+		want bool
+	}{
+		"pod": {
+			"pod-uid",
+			true,
+		},
+		"owner": {
+			"replicaset-uid",
+			true,
+		},
+		"other": {
+			"other-uid",
+			false,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			event := v1.Event{InvolvedObject: v1.ObjectReference{UID: types.UID(testCase.uid)}}
+
+			if got := tracker.involves(event); got != testCase.want {
+				t.Errorf("involves() = %t, want %t", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestEventFormat(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		event         v1.Event
+		wantObject    string
+		wantContainer string
+		wantText      string
+		wantColor     *color.Color
+	}{
+		"pod container": {
+			v1.Event{
+				InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "api-1", FieldPath: "spec.containers{api}"},
+				Type:           v1.EventTypeWarning,
+				Reason:         "BackOff",
+				Message:        "Back-off restarting failed container",
+			},
+			"api-1/api",
+			"api",
+			"Warning BackOff: Back-off restarting failed container",
+			output.Yellow,
+		},
+		"pod": {
+			v1.Event{
+				InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "api-1"},
+				Type:           v1.EventTypeNormal,
+				Reason:         "Scheduled",
+				Message:        "Successfully assigned",
+			},
+			"api-1",
+			"",
+			"Normal Scheduled: Successfully assigned",
+			output.Magenta,
+		},
+		"owner": {
+			v1.Event{
+				InvolvedObject: v1.ObjectReference{Kind: "ReplicaSet", Name: "api-7d9"},
+				Type:           v1.EventTypeNormal,
+				Reason:         "SuccessfulCreate",
+				Message:        "Created pod: api-1",
+			},
+			"replicaset/api-7d9",
+			"",
+			"Normal SuccessfulCreate: Created pod: api-1",
+			output.Magenta,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			object, container := eventObject(testCase.event)
+			if object != testCase.wantObject || container != testCase.wantContainer {
+				t.Errorf("eventObject() = (`%s`, `%s`), want (`%s`, `%s`)", object, container, testCase.wantObject, testCase.wantContainer)
+			}
+
+			if got := formatEvent(testCase.event, testCase.event.Message); got != testCase.wantText {
+				t.Errorf("formatEvent() = `%s`, want `%s`", got, testCase.wantText)
+			}
+
+			if got := eventColorOf(testCase.event); got != testCase.wantColor {
+				t.Errorf("eventColorOf() = %v, want %v", got, testCase.wantColor)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+var messageKeys = []string{"msg", "message"}
+
+func parseFields(content string) (map[string]any, bool) {
+	if !strings.HasPrefix(content, "{") {
+		return nil, false
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(content), &fields); err != nil {
+		return nil, false
+	}
+
+	return fields, true
+}
+
+// lookupField resolves a dotted key (e.g. `http.status`) in parsed JSON fields, case insensitive.
+func lookupField(fields map[string]any, key string) (any, bool) {
+	var current any = fields
+
+	for _, part := range strings.Split(key, ".") {
+		values, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = values[part]
+		if ok {
+			continue
+		}
+
+		for name, value := range values {
+			if strings.EqualFold(name, part) {
+				current, ok = value, true
+				break
+			}
+		}
+
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// messageOf returns the message of a JSON log, or the text itself
+func messageOf(fields map[string]any, text string) string {
+	for _, key := range messageKeys {
+		if value, ok := lookupField(fields, key); ok {
+			return fieldString(value)
+		}
+	}
+
+	return text
+}
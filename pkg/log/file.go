@@ -0,0 +1,109 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/output"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/util/exec"
+)
+
+// errTailUnread stops `tail` when its output is no longer read, e.g. for a line exceeding the scanner's buffer
+var errTailUnread = errors.New("output is no longer read, a line may exceed 64KB")
+
+type warnWriter struct {
+	outputter output.Outputter
+}
+
+func (ww warnWriter) Write(payload []byte) (int, error) {
+	ww.outputter.Warn("%s", strings.TrimSpace(string(payload)))
+
+	return len(payload), nil
+}
+
+func (l Logger) handleFiles(ctx context.Context, kube client.Kube, streams *streams, pod v1.Pod, container string) {
+	for _, path := range l.files {
+		streams.start(ctx, pod, streamKey{uid: pod.UID, container: container, file: path}, func(ctx context.Context) {
+			l.forPod(ctx, kube, pod).tailFile(ctx, kube, pod, container, path)
+		})
+	}
+}
+
+// tailFile streams a file inside the container by executing `tail`, without requiring a shell in the image
+func (l Logger) tailFile(ctx context.Context, kube client.Kube, pod v1.Pod, container, path string) {
+	request := kube.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(pod.Namespace).
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&v1.PodExecOptions{
+			Container: container,
+			Command:   l.tailCommand(path),
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	outputter := kube.Child(l.rawOutput, output.Green.Sprintf("[%s:%s]", l.prefix(pod.Name, container), path))
+
+	executor, err := remotecommand.NewSPDYExecutor(kube.Config, http.MethodPost, request.URL())
+	if err != nil {
+		outputter.Err("create executor: %s", err)
+		return
+	}
+
+	reader, writer := io.Pipe()
+	done := make(chan error, 1)
+
+	go func() {
+		defer close(done)
+
+		done <- executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+			Stdout: writer,
+			Stderr: warnWriter{outputter: outputter},
+		})
+
+		_ = writer.Close()
+	}()
+
+	source := l.newSource(kube, pod, container)
+	source.File = path
+
+	l.outputLog(reader, outputter, source)
+
+	// unblocks the exec's writes if output stopped before the end of the stream
+	_ = reader.CloseWithError(errTailUnread)
+
+	if err := <-done; err != nil && ctx.Err() == nil {
+		outputter.Err("%s", tailError(err))
+	}
+}
+
+// tailCommand follows the file by name, for surviving its rotation, unless not following
+func (l Logger) tailCommand(path string) []string {
+	if l.noFollow {
+		return []string{"tail", path}
+	}
+
+	return []string{"tail", "-F", path}
+}
+
+func tailError(err error) error {
+	var exitErr exec.CodeExitError
+	if errors.As(err, &exitErr) {
+		return fmt.Errorf("tail exited with code %d", exitErr.Code)
+	}
+
+	if message := err.Error(); strings.Contains(message, "executable file not found") || strings.Contains(message, "no such file or directory") {
+		return errors.New("`tail` is not available in the container's image")
+	}
+
+	return fmt.Errorf("exec tail: %w", err)
+}
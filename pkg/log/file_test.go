@@ -0,0 +1,114 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/concurrent"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/util/exec"
+)
+
+func TestTailCommand(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		noFollow bool
+		want     []string
+	}{
+		"follow": {
+			false,
+			[]string{"tail", "-F", "/var/log/app.log"},
+		},
+		"no follow": {
+			true,
+			[]string{"tail", "/var/log/app.log"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := (Logger{}).WithNoFollow(testCase.noFollow).tailCommand("/var/log/app.log"); !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("tailCommand() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestTailError(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		err  error
+		want string
+	}{
+		"exit code": {
+			exec.CodeExitError{Err: errors.New("command terminated"), Code: 1},
+			"tail exited with code 1",
+		},
+		"missing binary": {
+			errors.New(`exec: "tail": executable file not found in $PATH`),
+			"`tail` is not available in the container's image",
+		},
+		"other": {
+			errTailUnread,
+			"exec tail: output is no longer read, a line may exceed 64KB",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tailError(testCase.err).Error(); got != testCase.want {
+				t.Errorf("tailError() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestHandlePodFiles(t *testing.T) {
+	t.Parallel()
+
+	pod := v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{Name: "api"}, {Name: "worker"}, {Name: "batch"}},
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: []v1.ContainerStatus{
+				{Name: "api", State: v1.ContainerState{Running: &v1.ContainerStateRunning{}}},
+				{Name: "worker", State: v1.ContainerState{Waiting: &v1.ContainerStateWaiting{}}},
+				{Name: "batch", State: v1.ContainerState{Terminated: &v1.ContainerStateTerminated{}}},
+			},
+		},
+	}
+	pod.UID = "1234"
+
+	// streams are never granted a slot, for registering them without executing `tail`
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	streams := &streams{running: concurrent.NewSimple(), scheduler: newScheduler(1, time.Hour)}
+
+	Logger{}.WithFiles([]string{"/var/log/app.log"}).handlePod(ctx, client.Kube{}, streams, nil, pod)
+	streams.wait()
+
+	var got []streamKey
+
+	streams.active.Range(func(key, _ any) bool {
+		got = append(got, key.(streamKey))
+		return true
+	})
+
+	want := []streamKey{{uid: "1234", container: "api", file: "/var/log/app.log"}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("handlePod() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const orSeparator = "||"
+
+type operator string
+
+const (
+	exists      operator = ""
+	notExists   operator = "!"
+	equal       operator = "="
+	notEqual    operator = "!="
+	greater     operator = ">"
+	greaterOrEq operator = ">="
+	lower       operator = "<"
+	lowerOrEq   operator = "<="
+	matches     operator = "~"
+	notMatches  operator = "!~"
+)
+
+// operators are ordered for the two-chars ones to be checked first
+var operators = []operator{notEqual, greaterOrEq, lowerOrEq, notMatches, equal, greater, lower, matches}
+
+type predicate struct {
+	regexp   *regexp.Regexp
+	key      string
+	value    string
+	operator operator
+	number   float64
+}
+
+// FieldFilter is a set of predicates on JSON fields, it matches if any of them matches.
+type FieldFilter []predicate
+
+// ParseFieldFilter parses an expression like `level=error`, `status>=500`, `path~^/api`, `trace_id` or `!trace_id`.
+// Alternatives are separated by `||`, e.g. `level=error||status>=500`.
+func ParseFieldFilter(expression string) (FieldFilter, error) {
+	var output FieldFilter
+
+	for _, part := range strings.Split(expression, orSeparator) {
+		item, err := parsePredicate(strings.TrimSpace(part))
+		if err != nil {
+			return nil, fmt.Errorf("parse `%s`: %w", part, err)
+		}
+
+		output = append(output, item)
+	}
+
+	return output, nil
+}
+
+func parsePredicate(expression string) (predicate, error) {
+	if len(expression) == 0 {
+		return predicate{}, errors.New("empty expression")
+	}
+
+	for index := range expression {
+		for _, op := range operators {
+			if !strings.HasPrefix(expression[index:], string(op)) {
+				continue
+			}
+
+			item := predicate{
+				key:      strings.TrimSpace(expression[:index]),
+				operator: op,
+				value:    strings.TrimSpace(expression[index+len(op):]),
+			}
+
+			if len(item.key) == 0 {
+				return predicate{}, errors.New("no key")
+			}
+
+			var err error
+
+			switch op {
+			case greater, greaterOrEq, lower, lowerOrEq:
+				if item.number, err = strconv.ParseFloat(item.value, 64); err != nil {
+					return predicate{}, fmt.Errorf("parse number: %w", err)
+				}
+
+			case matches, notMatches:
+				if item.regexp, err = regexp.Compile(item.value); err != nil {
+					return predicate{}, fmt.Errorf("compile regexp: %w", err)
+				}
+			}
+
+			return item, nil
+		}
+	}
+
+	if key := strings.TrimPrefix(expression, string(notExists)); key != expression {
+		return predicate{key: key, operator: notExists}, nil
+	}
+
+	return predicate{key: expression, operator: exists}, nil
+}
+
+// Match checks if any predicate matches the given fields.
+func (ff FieldFilter) Match(fields map[string]any) bool {
+	for _, item := range ff {
+		if item.match(fields) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p predicate) match(fields map[string]any) bool {
+	value, ok := lookupField(fields, p.key)
+
+	switch p.operator {
+	case exists:
+		return ok
+	case notExists:
+		return !ok
+	}
+
+	if !ok {
+		return p.operator == notEqual || p.operator == notMatches
+	}
+
+	switch p.operator {
+	case equal:
+		return strings.EqualFold(fieldString(value), p.value)
+	case notEqual:
+		return !strings.EqualFold(fieldString(value), p.value)
+	case matches:
+		return p.regexp.MatchString(fieldString(value))
+	case notMatches:
+		return !p.regexp.MatchString(fieldString(value))
+	}
+
+	number, ok := fieldNumber(value)
+	if !ok {
+		return false
+	}
+
+	switch p.operator {
+	case greater:
+		return number > p.number
+	case greaterOrEq:
+		return number >= p.number
+	case lower:
+		return number < p.number
+	case lowerOrEq:
+		return number <= p.number
+	default:
+		return false
+	}
+}
+
+func fieldString(value any) string {
+	switch content := value.(type) {
+	case string:
+		return content
+	case float64:
+		return strconv.FormatFloat(content, 'f', -1, 64)
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%v", content)
+	}
+}
+
+func fieldNumber(value any) (float64, bool) {
+	switch content := value.(type) {
+	case float64:
+		return content, true
+	case string:
+		number, err := strconv.ParseFloat(content, 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func fieldFiltersMatch(filters []FieldFilter, text string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+
+	fields, ok := parseFields(text)
+	if !ok {
+		return false
+	}
+
+	for _, filter := range filters {
+		if !filter.Match(fields) {
+			return false
+		}
+	}
+
+	return true
+}
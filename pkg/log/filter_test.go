@@ -0,0 +1,141 @@
+package log
+
+import (
+	"testing"
+)
+
+func TestFieldFilterMatch(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		expression string
+		content    string
+	}
+
+	cases := map[string]struct {
+		args args
+		want bool
+	}{
+		"equal": {
+			args{
+				expression: "level=error",
+				content:    `{"level":"ERROR","msg":"hello"}`,
+			},
+			true,
+		},
+		"not equal": {
+			args{
+				expression: "level!=error",
+				content:    `{"level":"info","msg":"hello"}`,
+			},
+			true,
+		},
+		"numeric": {
+			args{
+				expression: "status>=500",
+				content:    `{"status":503}`,
+			},
+			true,
+		},
+		"numeric lower": {
+			args{
+				expression: "status>=500",
+				content:    `{"status":404}`,
+			},
+			false,
+		},
+		"numeric string": {
+			args{
+				expression: "status<400",
+				content:    `{"status":"200"}`,
+			},
+			true,
+		},
+		"regexp": {
+			args{
+				expression: "path~^/api",
+				content:    `{"path":"/api/users"}`,
+			},
+			true,
+		},
+		"nested": {
+			args{
+				expression: "http.request.method=POST",
+				content:    `{"http":{"request":{"method":"POST"}}}`,
+			},
+			true,
+		},
+		"exists": {
+			args{
+				expression: "trace_id",
+				content:    `{"msg":"hello"}`,
+			},
+			false,
+		},
+		"not exists": {
+			args{
+				expression: "!trace_id",
+				content:    `{"msg":"hello"}`,
+			},
+			true,
+		},
+		"or": {
+			args{
+				expression: "level=error||status>=500",
+				content:    `{"level":"info","status":502}`,
+			},
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			filter, err := ParseFieldFilter(testCase.args.expression)
+			if err != nil {
+				t.Fatalf("ParseFieldFilter() = %s", err)
+			}
+
+			if got := fieldFiltersMatch([]FieldFilter{filter}, testCase.args.content); got != testCase.want {
+				t.Errorf("Match() = %t, want %t", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestParseFieldFilter(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		expression string
+		wantErr    bool
+	}{
+		"valid": {
+			"status>500",
+			false,
+		},
+		"no key": {
+			"=error",
+			true,
+		},
+		"not a number": {
+			"status>=abc",
+			true,
+		},
+		"invalid regexp": {
+			"path~[",
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := ParseFieldFilter(testCase.expression); (err != nil) != testCase.wantErr {
+				t.Errorf("ParseFieldFilter() = %v, wantErr %t", err, testCase.wantErr)
+			}
+		})
+	}
+}
@@ -2,11 +2,23 @@ package log
 
 import (
 	"regexp"
+	"sort"
+	"strings"
 
 	"github.com/ViBiOh/kmux/pkg/output"
 	"github.com/fatih/color"
 )
 
+// highlights are the colors of each grep pattern, in the order they are given
+var highlights = []*color.Color{
+	output.Red,
+	output.Cyan,
+	output.Magenta,
+	output.Blue,
+	output.Green,
+	output.Yellow,
+}
+
 type Formatter func(a ...any) string
 
 func Format(text string, outputter *color.Color) string {
@@ -14,31 +26,77 @@ func Format(text string, outputter *color.Color) string {
 		return text
 	}
 
-	return outputter.Sprint(text)
+	if !strings.Contains(text, "\n") {
+		return outputter.Sprint(text)
+	}
+
+	// each line is colored on its own, because prefixes are printed between lines
+	lines := strings.Split(text, "\n")
+	for index, line := range lines {
+		if len(line) != 0 {
+			lines[index] = outputter.Sprint(line)
+		}
+	}
+
+	return strings.Join(lines, "\n")
 }
 
-func FormatGrep(text string, logFilter *regexp.Regexp, outputter *color.Color) string {
-	var greppedText string
-	var currentIndex int
+type match struct {
+	highlight *color.Color
+	start     int
+	end       int
+}
 
-	highlight := output.Red
-	if outputter == output.Red {
-		highlight = output.Yellow
+// FormatGrep highlights matches of every filter with its own color, the first match wins when they overlap
+func FormatGrep(text string, logFilters []*regexp.Regexp, outputter *color.Color) string {
+	var matches []match
+
+	for index, logFilter := range logFilters {
+		highlight := highlightOf(index, outputter)
+
+		for _, position := range logFilter.FindAllStringIndex(text, -1) {
+			matches = append(matches, match{start: position[0], end: position[1], highlight: highlight})
+		}
 	}
 
-	for _, index := range logFilter.FindAllStringIndex(text, -1) {
-		if index[0] != currentIndex {
-			greppedText += Format(text[currentIndex:index[0]], outputter)
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].start < matches[j].start
+	})
+
+	var greppedText strings.Builder
+	var currentIndex int
+
+	for _, item := range matches {
+		if item.start < currentIndex || item.start == item.end {
+			continue
 		}
 
-		greppedText += highlight.Sprint(text[index[0]:index[1]])
+		if item.start != currentIndex {
+			greppedText.WriteString(Format(text[currentIndex:item.start], outputter))
+		}
+
+		greppedText.WriteString(Format(text[item.start:item.end], item.highlight))
 
-		currentIndex = index[1]
+		currentIndex = item.end
 	}
 
 	if currentIndex != len(text) {
-		greppedText += Format(text[currentIndex:], outputter)
+		greppedText.WriteString(Format(text[currentIndex:], outputter))
+	}
+
+	return greppedText.String()
+}
+
+func highlightOf(index int, outputter *color.Color) *color.Color {
+	highlight := highlights[index%len(highlights)]
+
+	if highlight != outputter {
+		return highlight
+	}
+
+	if highlight == output.Red {
+		return output.Yellow
 	}
 
-	return greppedText
+	return output.Red
 }
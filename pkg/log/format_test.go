@@ -0,0 +1,151 @@
+package log
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/fatih/color"
+)
+
+// TestFormatGrep is not parallel, colors being forced for every test while it runs
+func TestFormatGrep(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = false
+
+	defer func() {
+		color.NoColor = noColor
+	}()
+
+	type args struct {
+		text      string
+		outputter *color.Color
+		patterns  []string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"no match": {
+			args{
+				text:     "GET /api 200",
+				patterns: []string{"500"},
+			},
+			"GET /api 200",
+		},
+		"single": {
+			args{
+				text:     "GET /api 500",
+				patterns: []string{"500"},
+			},
+			"GET /api " + output.Red.Sprint("500"),
+		},
+		"several matches": {
+			args{
+				text:     "500 GET /api 500",
+				patterns: []string{"500"},
+			},
+			output.Red.Sprint("500") + " GET /api " + output.Red.Sprint("500"),
+		},
+		"several patterns": {
+			args{
+				text:     "GET /api 500",
+				patterns: []string{"GET", "500"},
+			},
+			output.Red.Sprint("GET") + " /api " + output.Cyan.Sprint("500"),
+		},
+		"overlapping": {
+			args{
+				text:     "/api/v1/users",
+				patterns: []string{"api/v1", "v1/users"},
+			},
+			"/" + output.Red.Sprint("api/v1") + "/users",
+		},
+		"overlapping first match wins": {
+			args{
+				text:     "/api/v1/users",
+				patterns: []string{"v1/users", "api/v1"},
+			},
+			"/" + output.Cyan.Sprint("api/v1") + "/users",
+		},
+		"line color": {
+			args{
+				text:      "GET /api 500",
+				outputter: output.Red,
+				patterns:  []string{"500"},
+			},
+			output.Red.Sprint("GET /api ") + output.Yellow.Sprint("500"),
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			var regexes []*regexp.Regexp
+			for _, pattern := range testCase.args.patterns {
+				regexes = append(regexes, regexp.MustCompile(pattern))
+			}
+
+			if got := FormatGrep(testCase.args.text, regexes, testCase.args.outputter); got != testCase.want {
+				t.Errorf("FormatGrep() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestHighlightOf(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		outputter *color.Color
+		index     int
+	}
+
+	cases := map[string]struct {
+		args args
+		want *color.Color
+	}{
+		"first": {
+			args{
+				index: 0,
+			},
+			output.Red,
+		},
+		"second": {
+			args{
+				index: 1,
+			},
+			output.Cyan,
+		},
+		"cycle": {
+			args{
+				index: len(highlights),
+			},
+			output.Red,
+		},
+		"same as line": {
+			args{
+				index:     1,
+				outputter: output.Cyan,
+			},
+			output.Red,
+		},
+		"same as red line": {
+			args{
+				index:     0,
+				outputter: output.Red,
+			},
+			output.Yellow,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := highlightOf(testCase.args.index, testCase.args.outputter); got != testCase.want {
+				t.Errorf("highlightOf() = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package log
+
+import (
+	"regexp"
+
+	"github.com/fatih/color"
+)
+
+type contextLine struct {
+	outputter *color.Color
+	severity  string
+	timestamp string
+	text      string
+}
+
+// grepContext keeps the lines around grep's matches of a stream, like `grep -B -A`
+type grepContext struct {
+	before     []contextLine
+	beforeSize uint
+	afterSize  uint
+	after      uint
+	gap        bool
+	started    bool
+}
+
+func newGrepContext(beforeSize, afterSize uint) *grepContext {
+	return &grepContext{
+		beforeSize: beforeSize,
+		afterSize:  afterSize,
+	}
+}
+
+// skip stores a line not matching grep, it returns true if the line has to be printed as an after context
+func (gc *grepContext) skip(line contextLine) bool {
+	if gc.after > 0 {
+		gc.after--
+
+		return true
+	}
+
+	if gc.beforeSize == 0 {
+		gc.gap = gc.started
+
+		return false
+	}
+
+	if uint(len(gc.before)) == gc.beforeSize {
+		gc.before = gc.before[1:]
+		gc.gap = gc.started
+	}
+
+	gc.before = append(gc.before, line)
+
+	return false
+}
+
+// match returns the before context of a matching line and if a separator has to be printed
+func (gc *grepContext) match() ([]contextLine, bool) {
+	before := gc.before
+	separator := gc.gap && (gc.beforeSize > 0 || gc.afterSize > 0)
+
+	gc.before = nil
+	gc.gap = false
+	gc.started = true
+	gc.after = gc.afterSize
+
+	return before, separator
+}
+
+func (l Logger) grepMatch(text string) bool {
+	return grepMatch(l.logRegexes, text) != l.invertRegexp
+}
+
+func grepMatch(regexes []*regexp.Regexp, text string) bool {
+	for _, logRegexp := range regexes {
+		if logRegexp.MatchString(text) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,110 @@
+package log
+
+import (
+	"io"
+	"os"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+)
+
+// TestGrepContext is not parallel, printed lines being captured
+func TestGrepContext(t *testing.T) {
+	type args struct {
+		content   string
+		before    uint
+		after     uint
+		invert    bool
+		highlight bool
+	}
+
+	cases := map[string]struct {
+		args args
+		want []string
+	}{
+		"no context": {
+			args{
+				content: "a\nmatch\nb",
+			},
+			[]string{"match"},
+		},
+		"before": {
+			args{
+				before:  1,
+				content: "a\nb\nmatch\nc",
+			},
+			[]string{"b", "match"},
+		},
+		"after": {
+			args{
+				after:   1,
+				content: "a\nmatch\nb\nc",
+			},
+			[]string{"match", "b"},
+		},
+		"separator": {
+			args{
+				before:  1,
+				after:   1,
+				content: "match\na\nb\nc\nmatch\nd",
+			},
+			[]string{"match", "a", "--", "c", "match", "d"},
+		},
+		"contiguous": {
+			args{
+				before:  1,
+				after:   1,
+				content: "match\na\nb\nmatch",
+			},
+			[]string{"match", "a", "b", "match"},
+		},
+		"invert": {
+			args{
+				invert:  true,
+				content: "a\nmatch\nb",
+			},
+			[]string{"a", "b"},
+		},
+		"highlight": {
+			args{
+				highlight: true,
+				content:   "a\nmatch\nb",
+			},
+			[]string{"a", "match", "b"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			got := captureLines(func() {
+				Logger{}.
+					WithLogRegexes([]*regexp.Regexp{regexp.MustCompile("match")}).
+					WithGrepContext(testCase.args.before, testCase.args.after).
+					WithInvertRegexp(testCase.args.invert).
+					WithHighlight(testCase.args.highlight).
+					WithRawOutput(true).
+					outputLog(strings.NewReader(testCase.args.content), output.NewOutputter(""), source{})
+			})
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("outputEvents() = %#v, want %#v", got, testCase.want)
+			}
+		})
+	}
+}
+
+// captureLines returns the lines printed on stdout by the function, without their colors. Tests using it can't be parallel.
+func captureLines(print func()) []string {
+	var stdout strings.Builder
+
+	output.Redirect(&stdout, io.Discard)
+	defer output.Redirect(os.Stdout, os.Stderr)
+
+	print()
+	output.Flush()
+
+	return strings.Split(strings.TrimSuffix(ansiEscape.ReplaceAllString(stdout.String(), ""), "\n"), "\n")
+}
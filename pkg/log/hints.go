@@ -0,0 +1,100 @@
+package log
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/record"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	JSONFormat   = "json"
+	LogfmtFormat = "logfmt"
+	TextFormat   = "text"
+
+	logFormatAnnotation = "kmux.vibioh.fr/log-format"
+	levelKeyAnnotation  = "kmux.vibioh.fr/level-key"
+	statusKeyAnnotation = "kmux.vibioh.fr/status-key"
+)
+
+var LogFormats = []string{JSONFormat, LogfmtFormat, TextFormat}
+
+// forPod returns the logger to use for the pod's streams
+func (l Logger) forPod(ctx context.Context, kube client.Kube, pod v1.Pod) Logger {
+	return l.withRevision(ctx, kube, pod).withHints(kube, pod)
+}
+
+// withHints applies the parsing hints of the pod's annotations, unless given by flags
+func (l Logger) withHints(kube client.Kube, pod v1.Pod) Logger {
+	if format, ok := pod.Annotations[logFormatAnnotation]; ok && !l.forcedFormat {
+		if slices.Contains(LogFormats, format) {
+			l.logFormat = format
+		} else {
+			kube.Warn("unhandled log format `%s` of pod `%s`", format, pod.Name)
+		}
+	}
+
+	if keys, ok := pod.Annotations[levelKeyAnnotation]; ok && !l.forcedLevelKeys {
+		l.levelKeys = annotationValues(keys)
+	}
+
+	if keys, ok := pod.Annotations[statusKeyAnnotation]; ok && !l.forcedStatusKeys {
+		l.statusKeys = annotationValues(keys)
+	}
+
+	return l
+}
+
+// withRecordedHints applies the parsing hints resolved when the session was recorded, unless given by flags
+func (l Logger) withRecordedHints(hints *record.Hints) Logger {
+	if hints == nil {
+		return l
+	}
+
+	if len(hints.LogFormat) != 0 && !l.forcedFormat {
+		l.logFormat = hints.LogFormat
+	}
+
+	if len(hints.LevelKeys) != 0 && !l.forcedLevelKeys {
+		l.levelKeys = hints.LevelKeys
+	}
+
+	if len(hints.StatusKeys) != 0 && !l.forcedStatusKeys {
+		l.statusKeys = hints.StatusKeys
+	}
+
+	return l
+}
+
+func annotationValues(value string) []string {
+	var output []string
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); len(item) != 0 {
+			output = append(output, item)
+		}
+	}
+
+	return output
+}
+
+// colorKeys returns the keys used for coloring a log line, none for a text log
+func (l Logger) colorKeys() []string {
+	if l.logFormat == TextFormat {
+		return nil
+	}
+
+	return slices.Concat(l.levelKeys, l.statusKeys)
+}
+
+// jsonStatusKeys returns the keys of the HTTP status of a JSON log line, none for a text log
+func (l Logger) jsonStatusKeys() []string {
+	if l.logFormat == TextFormat {
+		return nil
+	}
+
+	return l.statusKeys
+}
@@ -1,7 +1,6 @@
 package log
 
 import (
-	"bufio"
 	"bytes"
 	"context"
 	"fmt"
@@ -13,31 +12,62 @@ import (
 	"github.com/ViBiOh/kmux/pkg/client"
 	"github.com/ViBiOh/kmux/pkg/concurrent"
 	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/ViBiOh/kmux/pkg/record"
 	"github.com/ViBiOh/kmux/pkg/resource"
 	"github.com/fatih/color"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/watch"
 )
 
+// Collector aggregates log lines instead of printing them, with the status keys resolved for their source
+type Collector interface {
+	collect(source source, outputter *color.Color, text string, statusKeys []string)
+}
+
 type Logger struct {
-	selector        map[string]string
-	logRegexes      []*regexp.Regexp
-	containerRegexp *regexp.Regexp
-	colorFilter     *color.Color
-	kind            string
-	name            string
-	jsonColorKeys   []string
-	since           int64
-	rawOutput       bool
-	dryRun          bool
-	invertRegexp    bool
-	noFollow        bool
-}
-
-func NewLogger(kind, name string, selector map[string]string, since time.Duration) Logger {
+	selector         map[string]string
+	renderer         Renderer
+	collector        Collector
+	dedup            *Dedup
+	trigger          *Trigger
+	recorder         *record.Recorder
+	revisions        *sync.Map
+	redactor         *Redactor
+	recordRedactor   *Redactor
+	logRegexes       []*regexp.Regexp
+	multilineStart   *regexp.Regexp
+	fieldFilters     []FieldFilter
+	containerRegexp  *regexp.Regexp
+	colorFilter      *color.Color
+	target           string
+	revision         string
+	outputFormat     string
+	logFormat        string
+	levelKeys        []string
+	statusKeys       []string
+	containerTypes   []resource.ContainerType
+	targets          []resource.Target
+	files            []string
+	since            int64
+	maxStreams       uint
+	sample           uint
+	beforeContext    uint
+	afterContext     uint
+	rawOutput        bool
+	dryRun           bool
+	invertRegexp     bool
+	highlight        bool
+	events           bool
+	noFollow         bool
+	multiline        bool
+	forcedFormat     bool
+	forcedLevelKeys  bool
+	forcedStatusKeys bool
+}
+
+func NewLogger(targets []resource.Target, selector map[string]string, since time.Duration) Logger {
 	return Logger{
-		kind:     kind,
-		name:     name,
+		targets:  targets,
 		selector: selector,
 		since:    int64(since.Seconds()),
 	}
@@ -55,32 +85,164 @@ func (l Logger) WithContainerRegexp(containerRegexp *regexp.Regexp) Logger {
 	return l
 }
 
+func (l Logger) WithContainerTypes(containerTypes []resource.ContainerType) Logger {
+	l.containerTypes = containerTypes
+
+	return l
+}
+
+func (l Logger) WithMaxStreams(maxStreams uint) Logger {
+	l.maxStreams = maxStreams
+
+	return l
+}
+
+func (l Logger) WithSample(sample uint) Logger {
+	l.sample = sample
+
+	return l
+}
+
 func (l Logger) WithNoFollow(noFollow bool) Logger {
 	l.noFollow = noFollow
 
 	return l
 }
 
+func (l Logger) WithMultiline(multiline bool, multilineStart *regexp.Regexp) Logger {
+	l.multiline = multiline || multilineStart != nil
+	l.multilineStart = multilineStart
+
+	return l
+}
+
 func (l Logger) WithLogRegexes(logRegexes []*regexp.Regexp) Logger {
 	l.logRegexes = logRegexes
 
 	return l
 }
 
+func (l Logger) WithFieldFilters(fieldFilters []FieldFilter) Logger {
+	l.fieldFilters = fieldFilters
+
+	return l
+}
+
 func (l Logger) WithInvertRegexp(invertRegexp bool) Logger {
 	l.invertRegexp = invertRegexp
 
 	return l
 }
 
+func (l Logger) WithGrepContext(beforeContext, afterContext uint) Logger {
+	l.beforeContext = beforeContext
+	l.afterContext = afterContext
+
+	return l
+}
+
+func (l Logger) WithHighlight(highlight bool) Logger {
+	l.highlight = highlight
+
+	return l
+}
+
 func (l Logger) WithColorFilter(colorFilter *color.Color) Logger {
 	l.colorFilter = colorFilter
 
 	return l
 }
 
-func (l Logger) WithJsonColorKeys(jsonColorKeys []string) Logger {
-	l.jsonColorKeys = jsonColorKeys
+// WithLevelKeys sets the keys of level in JSON, forced ones having precedence over pods' annotations
+func (l Logger) WithLevelKeys(levelKeys []string, forced bool) Logger {
+	l.levelKeys = levelKeys
+	l.forcedLevelKeys = forced
+
+	return l
+}
+
+// WithStatusKeys sets the keys of HTTP status in JSON, forced ones having precedence over pods' annotations
+func (l Logger) WithStatusKeys(statusKeys []string, forced bool) Logger {
+	l.statusKeys = statusKeys
+	l.forcedStatusKeys = forced
+
+	return l
+}
+
+// WithLogFormat sets the format of logs, having precedence over pods' annotations if not empty
+func (l Logger) WithLogFormat(logFormat string) Logger {
+	l.logFormat = logFormat
+	l.forcedFormat = len(logFormat) != 0
+
+	return l
+}
+
+func (l Logger) WithRenderer(renderer Renderer) Logger {
+	l.renderer = renderer
+
+	return l
+}
+
+func (l Logger) WithOutputFormat(outputFormat string) Logger {
+	l.outputFormat = outputFormat
+
+	return l
+}
+
+func (l Logger) WithEvents(events bool) Logger {
+	l.events = events
+
+	return l
+}
+
+func (l Logger) WithCollector(collector Collector) Logger {
+	l.collector = collector
+
+	return l
+}
+
+func (l Logger) WithDedup(dedup *Dedup) Logger {
+	l.dedup = dedup
+
+	return l
+}
+
+func (l Logger) WithTrigger(trigger *Trigger) Logger {
+	l.trigger = trigger
+
+	return l
+}
+
+func (l Logger) WithByRevision(byRevision bool) Logger {
+	l.revisions = nil
+	if byRevision {
+		l.revisions = &sync.Map{}
+	}
+
+	return l
+}
+
+func (l Logger) WithRecorder(recorder *record.Recorder) Logger {
+	l.recorder = recorder
+
+	return l
+}
+
+// WithRecordRedactor redacts recorded lines and events, whatever the output, recorded sessions being meant to be shared
+func (l Logger) WithRecordRedactor(redactor *Redactor) Logger {
+	l.recordRedactor = redactor
+
+	return l
+}
+
+func (l Logger) WithRedactor(redactor *Redactor) Logger {
+	l.redactor = redactor
+
+	return l
+}
+
+func (l Logger) WithFiles(files []string) Logger {
+	l.files = files
 
 	return l
 }
@@ -92,97 +254,168 @@ func (l Logger) WithRawOutput(rawOutput bool) Logger {
 }
 
 func (l Logger) Log(ctx context.Context, kube client.Kube) error {
-	podWatcher, err := resource.WatchPods(ctx, kube, l.kind, l.name, l.selector, l.dryRun || l.noFollow)
-	if err != nil {
-		return fmt.Errorf("watch pods: %w", err)
+	targets := l.targets
+	if len(targets) == 0 {
+		targets = []resource.Target{{}}
 	}
 
-	defer podWatcher.Stop()
-
-	var activeStreams sync.Map
+	podWatchers := make([]watch.Interface, 0, len(targets))
 
-	streaming := concurrent.NewSimple()
+	defer func() {
+		for _, podWatcher := range podWatchers {
+			podWatcher.Stop()
+		}
+	}()
 
-	for event := range podWatcher.ResultChan() {
-		pod, ok := event.Object.(*v1.Pod)
-		if !ok {
-			continue
+	for _, target := range targets {
+		podWatcher, err := resource.WatchPods(ctx, kube, target.Kind, target.Name, l.selector, l.dryRun || l.noFollow)
+		if err != nil {
+			return fmt.Errorf("watch pods of `%s`: %w", target, err)
 		}
 
-		streamCancel, ok := activeStreams.Load(pod.UID)
+		podWatchers = append(podWatchers, podWatcher)
+	}
+
+	var tracker *eventTracker
 
-		if event.Type == watch.Deleted || event.Type == watch.Error || pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
-			if ok {
-				streamCancel.(context.CancelFunc)()
+	if l.events && !l.dryRun {
+		tracker = &eventTracker{}
 
-				if event.Type == watch.Deleted {
-					activeStreams.Delete(pod.UID)
+		if !l.noFollow {
+			namespaces := make(map[string]struct{})
+
+			for _, target := range targets {
+				namespace := kube.Namespace
+				if resource.IsNamespace(target.Kind) && len(target.Name) != 0 {
+					namespace = target.Name
 				}
-			} else if pod.Status.Phase == v1.PodSucceeded || pod.Status.Phase == v1.PodFailed {
-				l.handlePod(ctx, kube, &activeStreams, streaming, *pod)
-			}
 
-			continue
+				if _, ok := namespaces[namespace]; ok {
+					continue
+				}
+
+				namespaces[namespace] = struct{}{}
+
+				eventWatcher, err := l.watchEvents(ctx, kube, namespace, tracker)
+				if err != nil {
+					return fmt.Errorf("watch events: %w", err)
+				}
+
+				defer eventWatcher.Stop()
+			}
 		}
+	}
 
-		if ok || pod.Status.Phase == v1.PodPending {
-			continue
+	streams := newStreams(l.maxStreams)
+	watching := concurrent.NewSimple()
+
+	var podSampler *sampler
+	if l.sample != 0 {
+		podSampler = newSampler(l.sample)
+	}
+
+	for index, podWatcher := range podWatchers {
+		targetLogger := l
+		if len(l.targets) > 1 {
+			targetLogger.target = targets[index].String()
 		}
 
-		l.handlePod(ctx, kube, &activeStreams, streaming, *pod)
+		watching.Go(func() {
+			for event := range podWatcher.ResultChan() {
+				pod, ok := event.Object.(*v1.Pod)
+				if !ok {
+					continue
+				}
+
+				if event.Type == watch.Deleted || event.Type == watch.Error {
+					streams.stop(pod.UID)
+
+					if podSampler != nil {
+						if replacement, ok := podSampler.remove(*pod); ok {
+							targetLogger.handlePod(ctx, kube, streams, tracker, replacement)
+						}
+					}
+
+					continue
+				}
+
+				if podSampler != nil && !podSampler.follow(*pod) {
+					continue
+				}
+
+				targetLogger.handlePod(ctx, kube, streams, tracker, *pod)
+			}
+		})
 	}
 
-	streaming.Wait()
+	watching.Wait()
+	streams.wait()
 
 	return nil
 }
 
-func (l Logger) handlePod(ctx context.Context, kube client.Kube, activeStreams *sync.Map, streaming *concurrent.Simple, pod v1.Pod) {
-	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
-		if !resource.IsContainedSelected(container, l.containerRegexp) {
+func (l Logger) handlePod(ctx context.Context, kube client.Kube, streams *streams, tracker *eventTracker, pod v1.Pod) {
+	if tracker != nil {
+		l.trackPod(ctx, kube, tracker, pod)
+	}
+
+	for _, container := range resource.Containers(pod.Spec) {
+		if !resource.IsContainedSelected(container, l.containerRegexp, l.containerTypes) {
 			continue
 		}
 
-		container := container
-
 		if l.dryRun {
-			kube.Info("%s %s", output.Green.Sprintf("[%s/%s]", pod.Name, container.Name), output.Yellow.Sprint("Found!"))
+			kube.Info("%s %s", output.Green.Sprintf("[%s]", l.prefix(pod.Name, container.Name)), output.Yellow.Sprint("Found!"))
 			continue
 		}
 
-		streaming.Go(func() {
-			if pod.Status.Phase != v1.PodRunning {
-				l.logPod(ctx, kube, pod.Namespace, pod.Name, container.Name)
-				return
+		status, _ := resource.ContainerStatus(pod, container.Name)
+		state := status.State
+
+		if len(l.files) != 0 {
+			if state.Running != nil {
+				l.handleFiles(ctx, kube, streams, pod, container.Name)
 			}
 
-			streamCtx, streamCancel := context.WithCancel(ctx)
-			activeStreams.Store(pod.UID, streamCancel)
-			defer streamCancel()
+			continue
+		}
+
+		key := streamKey{uid: pod.UID, container: container.Name}
 
-			l.streamPod(streamCtx, kube, pod.Namespace, pod.Name, container.Name)
-		})
+		switch {
+		case state.Running != nil:
+			streams.start(ctx, pod, key, func(ctx context.Context) {
+				l.forPod(ctx, kube, pod).streamPod(ctx, kube, pod, container.Name)
+			})
+
+		case state.Terminated != nil:
+			streams.dump(ctx, pod, key, func(ctx context.Context) {
+				l.forPod(ctx, kube, pod).logPod(ctx, kube, pod, container.Name)
+			})
+		}
 	}
 }
 
-func (l Logger) logPod(ctx context.Context, kube client.Kube, namespace, name, container string) {
-	content, err := kube.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{
+func (l Logger) logPod(ctx context.Context, kube client.Kube, pod v1.Pod, container string) {
+	content, err := kube.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
 		SinceSeconds: &l.since,
 		Container:    container,
+		Timestamps:   l.withTimestamps(),
 	}).DoRaw(ctx)
 	if err != nil {
 		kube.Err("get logs: %s", err)
 		return
 	}
 
-	l.outputLog(bytes.NewReader(content), l.logOutputter(kube, name, container))
+	l.outputLog(bytes.NewReader(content), l.logOutputter(kube, pod.Name, container), l.newSource(kube, pod, container))
 }
 
-func (l Logger) streamPod(ctx context.Context, kube client.Kube, namespace, name, container string) {
-	stream, err := kube.CoreV1().Pods(namespace).GetLogs(name, &v1.PodLogOptions{
+func (l Logger) streamPod(ctx context.Context, kube client.Kube, pod v1.Pod, container string) {
+	stream, err := kube.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{
 		Follow:       !l.noFollow,
 		SinceSeconds: &l.since,
 		Container:    container,
+		Timestamps:   l.withTimestamps(),
 	}).Stream(ctx)
 	if err != nil {
 		kube.Err("stream logs: %s", err)
@@ -195,58 +428,167 @@ func (l Logger) streamPod(ctx context.Context, kube client.Kube, namespace, name
 		}
 	}()
 
-	l.outputLog(stream, l.logOutputter(kube, name, container))
+	l.outputLog(stream, l.logOutputter(kube, pod.Name, container), l.newSource(kube, pod, container))
 }
 
 func (l Logger) logOutputter(kube client.Kube, name, container string) output.Outputter {
-	return kube.Child(l.rawOutput, output.Green.Sprintf("[%s/%s]", name, container))
+	return kube.Child(l.rawOutput, output.Green.Sprintf("[%s]", l.prefix(name, container)))
 }
 
-func (l Logger) outputLog(reader io.Reader, outputter output.Outputter) {
-	if !l.rawOutput {
+// prefix identifies the container, with the revision and the target resource when many are streamed
+func (l Logger) prefix(name, container string) string {
+	prefix := name + "/" + container
+
+	if len(l.revision) != 0 {
+		prefix = "rev" + l.revision + " " + prefix
+	}
+
+	if len(l.target) != 0 {
+		prefix = l.target + " " + prefix
+	}
+
+	return prefix
+}
+
+// withTimestamps returns true if Kubernetes' timestamps of lines are needed
+func (l Logger) withTimestamps() bool {
+	return l.outputFormat == NDJSONFormat || l.recorder != nil || output.Shipping()
+}
+
+func (l Logger) outputLog(reader io.Reader, outputter output.Outputter, source source) {
+	l.outputEvents(l.scanEvents(reader, source), outputter, source)
+}
+
+func (l Logger) outputEvents(events <-chan logEvent, outputter output.Outputter, source source) {
+	if !l.rawOutput && l.collector == nil {
 		outputter.Warn("Log...")
 		defer outputter.Warn("Log ended.")
 	}
 
-	streamScanner := bufio.NewScanner(reader)
-	streamScanner.Split(bufio.ScanLines)
-
 	var colorOutputter *color.Color
 
-	for streamScanner.Scan() {
-		text := streamScanner.Text()
+	grepContext := newGrepContext(l.beforeContext, l.afterContext)
 
-		colorOutputter = ColorOfJSON(text, l.jsonColorKeys...)
+	var previousKey string
+	var previousPrinted bool
+	var repeated uint
+
+	printRepeated := l.repeatedPrinter(outputter, source)
+
+	defer func() {
+		if repeated != 0 {
+			printRepeated(repeated, "")
+		}
+
+		if l.dedup != nil && l.dedup.window != 0 {
+			l.dedup.flushSource(source.String())
+		}
+	}()
+
+	colorKeys := l.colorKeys()
+	statusKeys := l.jsonStatusKeys()
+
+	for event := range events {
+		text := event.text
+
+		if l.redactor != nil {
+			text = l.redactor.Redact(text)
+		}
+
+		if l.trigger != nil {
+			l.trigger.check(source, event.text, text)
+		}
+
+		// a logfmt line is handled like a JSON one for its fields, but printed as-is
+		fieldsText := text
+		if l.logFormat == LogfmtFormat {
+			fieldsText = logfmtToJSON(text)
+		}
+
+		level := valueOfJSON(fieldsText, colorKeys...)
+		colorOutputter = colorOfValue(level)
 
 		if colorIsGreater(colorOutputter, l.colorFilter) {
 			continue
 		}
 
-		if len(l.logRegexes) == 0 {
-			outputter.Std("%s", Format(text, colorOutputter))
+		if !fieldFiltersMatch(l.fieldFilters, fieldsText) {
+			continue
+		}
+
+		if l.renderer != nil {
+			if rendered := l.renderer(fieldsText); rendered != fieldsText {
+				text = rendered
+			}
+		}
+
+		line := contextLine{outputter: colorOutputter, severity: severityOfValue(level), timestamp: event.timestamp, text: text}
+
+		if !l.highlight && len(l.logRegexes) != 0 && !l.grepMatch(text) {
+			if l.collector == nil && grepContext.skip(line) {
+				l.printLog(outputter, source, line)
+			}
 
 			continue
 		}
 
-		if !l.grepMatch(text) {
+		if l.collector != nil {
+			l.collector.collect(source, colorOutputter, fieldsText, statusKeys)
+
 			continue
 		}
 
-		greppedText := text
-		for _, logRegexp := range l.logRegexes {
-			greppedText = FormatGrep(greppedText, logRegexp, colorOutputter)
+		if l.dedup != nil {
+			key := l.dedup.key(text)
+
+			if previousPrinted && key == previousKey {
+				repeated++
+
+				continue
+			}
+
+			if repeated != 0 {
+				printRepeated(repeated, "")
+				repeated = 0
+			}
+
+			// a line collapsed by the window doesn't start a run of consecutive repeats, its copies being counted by the window
+			previousKey, previousPrinted = key, l.dedup.seen(key, source.String(), printRepeated)
+
+			if !previousPrinted {
+				continue
+			}
+		}
+
+		before, separator := grepContext.match()
+		if separator && l.outputFormat != NDJSONFormat {
+			outputter.Std("%s", output.Cyan.Sprint("--"))
 		}
 
-		outputter.Std("%s", greppedText)
+		for _, beforeLine := range before {
+			l.printLog(outputter, source, beforeLine)
+		}
+
+		l.printLog(outputter, source, line)
 	}
 }
 
-func (l Logger) grepMatch(text string) bool {
-	for _, logRegexp := range l.logRegexes {
-		if logRegexp.MatchString(text) {
-			return !l.invertRegexp
-		}
+func (l Logger) printLog(outputter output.Outputter, source source, line contextLine) {
+	if output.Shipping() {
+		output.Ship(source.shipped(line))
+	}
+
+	if l.outputFormat == NDJSONFormat {
+		output.Std("", "%s", source.envelope(line.timestamp, line.severity, line.text))
+
+		return
+	}
+
+	if len(l.logRegexes) == 0 {
+		outputter.Std("%s", Format(line.text, line.outputter))
+
+		return
 	}
 
-	return l.invertRegexp
+	outputter.Std("%s", FormatGrep(line.text, l.logRegexes, line.outputter))
 }
@@ -0,0 +1,100 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// logfmtToJSON converts a `key=value key2="quoted value"` line to a JSON object, for handling it like a JSON log.
+// The text is returned as-is if it's not in logfmt.
+func logfmtToJSON(text string) string {
+	pairs, ok := parseLogfmt(text)
+	if !ok {
+		return text
+	}
+
+	var buffer bytes.Buffer
+	buffer.WriteString("{")
+
+	for index, pair := range pairs {
+		if index > 0 {
+			buffer.WriteString(",")
+		}
+
+		key, _ := json.Marshal(pair.key)
+		buffer.Write(key)
+		buffer.WriteString(":")
+
+		if _, err := strconv.ParseFloat(pair.value, 64); err == nil && !pair.quoted && json.Valid([]byte(pair.value)) {
+			buffer.WriteString(pair.value)
+			continue
+		}
+
+		value, _ := json.Marshal(pair.value)
+		buffer.Write(value)
+	}
+
+	buffer.WriteString("}")
+
+	return buffer.String()
+}
+
+type logfmtPair struct {
+	key    string
+	value  string
+	quoted bool
+}
+
+func parseLogfmt(text string) ([]logfmtPair, bool) {
+	var pairs []logfmtPair
+
+	text = strings.TrimSpace(text)
+
+	for len(text) != 0 {
+		end := strings.IndexAny(text, "= ")
+		if end <= 0 || text[end] != '=' {
+			return nil, false
+		}
+
+		pair := logfmtPair{key: text[:end]}
+		text = text[end+1:]
+
+		if strings.HasPrefix(text, `"`) {
+			value, rest, ok := cutQuoted(text)
+			if !ok {
+				return nil, false
+			}
+
+			pair.value, pair.quoted, text = value, true, rest
+		} else {
+			value, rest, _ := strings.Cut(text, " ")
+			pair.value, text = value, rest
+		}
+
+		pairs = append(pairs, pair)
+		text = strings.TrimLeft(text, " ")
+	}
+
+	return pairs, len(pairs) != 0
+}
+
+func cutQuoted(text string) (string, string, bool) {
+	for index := 1; index < len(text); index++ {
+		switch text[index] {
+		case '\\':
+			index++
+
+		case '"':
+			value, err := strconv.Unquote(text[:index+1])
+			if err != nil {
+				return "", "", false
+			}
+
+			return value, text[index+1:], true
+		}
+	}
+
+	return "", "", false
+}
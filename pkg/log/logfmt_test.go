@@ -0,0 +1,91 @@
+package log
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+)
+
+func TestLogfmtToJSON(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		text string
+		want string
+	}{
+		"text": {
+			"GET /api/users 200",
+			"GET /api/users 200",
+		},
+		"simple": {
+			`level=error status=503 msg="upstream \"api\" unavailable"`,
+			`{"level":"error","status":503,"msg":"upstream \"api\" unavailable"}`,
+		},
+		"quoted number": {
+			`id="42" ratio=NaN`,
+			`{"id":"42","ratio":"NaN"}`,
+		},
+		"unterminated": {
+			`msg="hello`,
+			`msg="hello`,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := logfmtToJSON(testCase.text); got != testCase.want {
+				t.Errorf("logfmtToJSON() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
+
+// TestOutputLogfmt is not parallel, printed lines being captured
+func TestOutputLogfmt(t *testing.T) {
+	content := "level=error msg=\"boom here\" status=500\nlevel=info msg=started status=200"
+
+	cases := map[string]struct {
+		filter string
+		want   []string
+	}{
+		"printed as-is": {
+			"",
+			[]string{`level=error msg="boom here" status=500`, "level=info msg=started status=200"},
+		},
+		"filtered on fields": {
+			"status>=500",
+			[]string{`level=error msg="boom here" status=500`},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			var filters []FieldFilter
+
+			if len(testCase.filter) != 0 {
+				filter, err := ParseFieldFilter(testCase.filter)
+				if err != nil {
+					t.Fatalf("ParseFieldFilter() = %s", err)
+				}
+
+				filters = append(filters, filter)
+			}
+
+			got := captureLines(func() {
+				Logger{}.
+					WithLogFormat(LogfmtFormat).
+					WithFieldFilters(filters).
+					WithRawOutput(true).
+					outputLog(strings.NewReader(content), output.NewOutputter(""), source{})
+			})
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("outputLog() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
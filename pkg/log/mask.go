@@ -0,0 +1,27 @@
+package log
+
+import (
+	"regexp"
+)
+
+// masks are applied in order, the most specific first
+var masks = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<TIME>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<UUID>"},
+	{regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}(:\d+)?\b`), "<IP>"},
+	{regexp.MustCompile(`\b\d+(\.\d+)?(ns|us|µs|ms|s|m|h)\b`), "<DURATION>"},
+	{regexp.MustCompile(`\b(0x)?[0-9a-fA-F]{16,}\b`), "<HEX>"},
+	{regexp.MustCompile(`\b\d+(\.\d+)?\b`), "<NUM>"},
+}
+
+// maskText replaces variable parts of a message (timestamps, IDs, numbers, etc.) by placeholders
+func maskText(message string) string {
+	for _, mask := range masks {
+		message = mask.pattern.ReplaceAllString(message, mask.replacement)
+	}
+
+	return message
+}
@@ -0,0 +1,191 @@
+package log
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	// multilineFlushDelay is the idle duration after which a pending event is printed, for not waiting for the next line
+	multilineFlushDelay = 250 * time.Millisecond
+
+	// multilineMaxLines and multilineMaxBytes bound an event, for a start regexp never matching or a stream never idle
+	multilineMaxLines = 1000
+	multilineMaxBytes = 256 << 10
+)
+
+var (
+	goroutineHeader = regexp.MustCompile(`^goroutine \d+ \[`)
+
+	continuationPrefixes = []string{
+		"Caused by:",
+		"Suppressed:",
+		"During handling of the above exception",
+		"The above exception was the direct cause",
+	}
+)
+
+const pythonTraceback = "Traceback (most recent call last):"
+
+type logEvent struct {
+	timestamp string
+	text      string
+}
+
+func (e logEvent) trimmed() logEvent {
+	e.text = strings.TrimRight(e.text, "\n")
+
+	return e
+}
+
+// multiline determines if a line continues the current event, it's stateful for a given stream
+type multiline struct {
+	start     *regexp.Regexp
+	goroutine bool
+	traceback bool
+	chained   bool
+}
+
+func (m *multiline) continues(line string) bool {
+	if m.start != nil {
+		return !m.start.MatchString(line)
+	}
+
+	switch {
+	case m.traceback:
+		// the first line not indented is the exception of the traceback, and closes it
+		m.traceback = startsWithSpace(line)
+		return true
+
+	case len(line) == 0:
+		m.goroutine = false
+		return true
+
+	case goroutineHeader.MatchString(line):
+		m.goroutine = true
+		return true
+
+	case line == pythonTraceback:
+		m.traceback = true
+		chained := m.chained
+		m.chained = false
+
+		return chained
+
+	case m.goroutine, startsWithSpace(line):
+		return true
+	}
+
+	for _, prefix := range continuationPrefixes {
+		if strings.HasPrefix(line, prefix) {
+			m.chained = true
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *multiline) reset() {
+	m.goroutine = false
+	m.traceback = false
+	m.chained = false
+}
+
+func startsWithSpace(line string) bool {
+	return strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+}
+
+func (l Logger) scanEvents(reader io.Reader, source source) <-chan logEvent {
+	lines := make(chan logEvent)
+
+	go func() {
+		defer close(lines)
+
+		streamScanner := bufio.NewScanner(reader)
+		streamScanner.Split(bufio.ScanLines)
+
+		var recorded bool
+
+		for streamScanner.Scan() {
+			event := logEvent{text: streamScanner.Text()}
+
+			if l.withTimestamps() {
+				event.timestamp, event.text = splitTimestamp(event.text)
+			}
+
+			if l.recorder != nil {
+				l.recordLog(source, event, !recorded)
+				recorded = true
+			}
+
+			lines <- event
+		}
+	}()
+
+	return l.groupEvents(lines)
+}
+
+func (l Logger) groupEvents(lines <-chan logEvent) <-chan logEvent {
+	if !l.multiline {
+		return lines
+	}
+
+	return groupEvents(lines, &multiline{start: l.multilineStart})
+}
+
+func groupEvents(lines <-chan logEvent, grouper *multiline) <-chan logEvent {
+	events := make(chan logEvent)
+
+	go func() {
+		defer close(events)
+
+		var current logEvent
+		var pending bool
+		var count int
+
+		flush := time.NewTimer(multilineFlushDelay)
+		defer flush.Stop()
+
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					if pending {
+						events <- current.trimmed()
+					}
+
+					return
+				}
+
+				if grouper.continues(line.text) && pending && count < multilineMaxLines && len(current.text)+len(line.text) < multilineMaxBytes {
+					current.text += "\n" + line.text
+					count++
+				} else {
+					if pending {
+						events <- current.trimmed()
+					}
+
+					current = line
+					pending = true
+					count = 1
+				}
+
+				flush.Reset(multilineFlushDelay)
+
+			case <-flush.C:
+				if pending {
+					events <- current.trimmed()
+					pending = false
+				}
+
+				grouper.reset()
+			}
+		}
+	}()
+
+	return events
+}
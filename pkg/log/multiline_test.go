@@ -0,0 +1,111 @@
+package log
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestGroupEvents(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		start   *regexp.Regexp
+		content string
+	}
+
+	cases := map[string]struct {
+		args args
+		want []string
+	}{
+		"single lines": {
+			args{
+				content: "first\nsecond",
+			},
+			[]string{"first", "second"},
+		},
+		"java": {
+			args{
+				content: "Exception in thread \"main\" java.lang.IllegalStateException: boom\n\tat com.example.Main.main(Main.java:10)\nCaused by: java.lang.NullPointerException\n\t... 1 more\nnext",
+			},
+			[]string{"Exception in thread \"main\" java.lang.IllegalStateException: boom\n\tat com.example.Main.main(Main.java:10)\nCaused by: java.lang.NullPointerException\n\t... 1 more", "next"},
+		},
+		"python": {
+			args{
+				content: "Traceback (most recent call last):\n  File \"main.py\", line 1, in <module>\nValueError: boom\nnext",
+			},
+			[]string{"Traceback (most recent call last):\n  File \"main.py\", line 1, in <module>\nValueError: boom", "next"},
+		},
+		"goroutine": {
+			args{
+				content: "panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x1d\n\nnext",
+			},
+			[]string{"panic: boom\n\ngoroutine 1 [running]:\nmain.main()\n\t/app/main.go:5 +0x1d", "next"},
+		},
+		"custom start": {
+			args{
+				start:   regexp.MustCompile(`^\d{4}-`),
+				content: "2024-01-01 first\nsecond\n2024-01-02 third",
+			},
+			[]string{"2024-01-01 first\nsecond", "2024-01-02 third"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			logger := Logger{}.WithMultiline(true, testCase.args.start)
+
+			var got []string
+			for event := range logger.scanEvents(strings.NewReader(testCase.args.content), source{}) {
+				got = append(got, event.text)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("groupEvents() = %#v, want %#v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestGroupEventsCap(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		line  string
+		lines int
+		want  []int
+	}{
+		"lines": {
+			"x",
+			multilineMaxLines*2 + 1,
+			[]int{multilineMaxLines, multilineMaxLines, 1},
+		},
+		"bytes": {
+			strings.Repeat("x", multilineMaxBytes/8),
+			10,
+			[]int{7, 3},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			// start never matching, every line continuing the first event
+			logger := Logger{}.WithMultiline(true, regexp.MustCompile("^never$"))
+			content := strings.Repeat(testCase.line+"\n", testCase.lines)
+
+			var got []int
+			for event := range logger.scanEvents(strings.NewReader(content), source{}) {
+				got = append(got, strings.Count(event.text, "\n")+1)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("groupEvents() = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/output"
+	v1 "k8s.io/api/core/v1"
+)
+
+const NDJSONFormat = "ndjson"
+
+type source struct {
+	Context   string `json:"context,omitempty"`
+	Resource  string `json:"resource,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	Namespace string `json:"namespace"`
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Node      string `json:"node,omitempty"`
+	File      string `json:"file,omitempty"`
+}
+
+func (l Logger) newSource(kube client.Kube, pod v1.Pod, container string) source {
+	return source{
+		Context:   kube.Name,
+		Resource:  l.target,
+		Revision:  l.revision,
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Container: container,
+		Node:      pod.Spec.NodeName,
+	}
+}
+
+func (s source) String() string {
+	name := s.Pod + "/" + s.Container
+	if len(s.Context) != 0 {
+		return s.Context + "/" + name
+	}
+
+	return name
+}
+
+type envelope struct {
+	source
+	Message   any    `json:"message"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Severity  string `json:"severity"`
+}
+
+func (s source) envelope(timestamp, severity, text string) string {
+	item := envelope{
+		source:    s,
+		Timestamp: timestamp,
+		Severity:  severity,
+		Message:   text,
+	}
+
+	if strings.HasPrefix(text, "{") && json.Valid([]byte(text)) {
+		item.Message = json.RawMessage(text)
+	}
+
+	payload, err := json.Marshal(item)
+	if err != nil {
+		return text
+	}
+
+	return string(payload)
+}
+
+// shipped returns the line for sinks, labeled by its source
+func (s source) shipped(line contextLine) output.Line {
+	timestamp, err := time.Parse(time.RFC3339Nano, line.timestamp)
+	if err != nil {
+		timestamp = time.Now()
+	}
+
+	labels := make(map[string]string)
+
+	for key, value := range map[string]string{
+		"context":   s.Context,
+		"resource":  s.Resource,
+		"revision":  s.Revision,
+		"namespace": s.Namespace,
+		"pod":       s.Pod,
+		"container": s.Container,
+		"node":      s.Node,
+		"file":      s.File,
+	} {
+		if len(value) != 0 {
+			labels[key] = value
+		}
+	}
+
+	return output.Line{
+		Time:     timestamp,
+		Labels:   labels,
+		Severity: line.severity,
+		Message:  line.text,
+	}
+}
+
+// splitTimestamp extracts the timestamp added by kubelet when asked with `timestamps=true`
+func splitTimestamp(text string) (string, string) {
+	timestamp, content, found := strings.Cut(text, " ")
+	if !found {
+		timestamp = text
+	}
+
+	if _, err := time.Parse(time.RFC3339Nano, timestamp); err != nil {
+		return "", text
+	}
+
+	return timestamp, content
+}
@@ -0,0 +1,53 @@
+package log
+
+import "testing"
+
+func TestEnvelope(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		timestamp string
+		severity  string
+		text      string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"text": {
+			args{
+				timestamp: "2024-05-01T12:00:00Z",
+				severity:  "info",
+				text:      `started "api"`,
+			},
+			`{"context":"prod","namespace":"default","pod":"api-1","container":"api","message":"started \"api\"","timestamp":"2024-05-01T12:00:00Z","severity":"info"}`,
+		},
+		"embedded json": {
+			args{
+				severity: "error",
+				text:     `{"level":"error","msg":"failed"}`,
+			},
+			`{"context":"prod","namespace":"default","pod":"api-1","container":"api","message":{"level":"error","msg":"failed"},"severity":"error"}`,
+		},
+		"invalid json": {
+			args{
+				severity: "info",
+				text:     `{"msg":`,
+			},
+			`{"context":"prod","namespace":"default","pod":"api-1","container":"api","message":"{\"msg\":","severity":"info"}`,
+		},
+	}
+
+	instance := source{Context: "prod", Namespace: "default", Pod: "api-1", Container: "api"}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := instance.envelope(testCase.args.timestamp, testCase.args.severity, testCase.args.text); got != testCase.want {
+				t.Errorf("envelope() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
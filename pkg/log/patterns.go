@@ -0,0 +1,164 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/fatih/color"
+)
+
+const (
+	wildcard            = "<*>"
+	patternSimilarity   = 0.5
+	patternExamples     = 2
+	patternMaxTokenized = 1024
+)
+
+type patternCluster struct {
+	contexts map[string]uint
+	tokens   []string
+	examples []string
+	total    uint
+}
+
+// Patterns clusters log messages into templates, in a Drain-like way: messages with the same number
+// of tokens and the same first token are merged when at least half of their tokens are equal.
+type Patterns struct {
+	groups map[string][]*patternCluster
+	mutex  sync.Mutex
+}
+
+func NewPatterns() *Patterns {
+	return &Patterns{
+		groups: make(map[string][]*patternCluster),
+	}
+}
+
+func (p *Patterns) collect(source source, _ *color.Color, text string, _ []string) {
+	fields, _ := parseFields(text)
+	message := messageOf(fields, text)
+
+	tokens := tokenize(message)
+	if len(tokens) == 0 {
+		return
+	}
+
+	groupKey := fmt.Sprintf("%d %s", len(tokens), tokens[0])
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var best *patternCluster
+	var bestSimilarity float64
+
+	for _, cluster := range p.groups[groupKey] {
+		if similarity := cluster.similarity(tokens); similarity > bestSimilarity {
+			best = cluster
+			bestSimilarity = similarity
+		}
+	}
+
+	if best == nil || bestSimilarity < patternSimilarity {
+		best = &patternCluster{
+			tokens:   tokens,
+			contexts: make(map[string]uint),
+		}
+
+		p.groups[groupKey] = append(p.groups[groupKey], best)
+	} else {
+		best.merge(tokens)
+	}
+
+	best.total++
+	best.contexts[source.Context]++
+
+	if len(best.examples) < patternExamples {
+		best.examples = append(best.examples, truncate(message))
+	}
+}
+
+// Print outputs the templates ranked by count
+func (p *Patterns) Print() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	var clusters []*patternCluster
+	for _, group := range p.groups {
+		clusters = append(clusters, group...)
+	}
+
+	sort.Slice(clusters, func(i, j int) bool {
+		if clusters[i].total != clusters[j].total {
+			return clusters[i].total > clusters[j].total
+		}
+
+		return clusters[i].template() < clusters[j].template()
+	})
+
+	var builder strings.Builder
+
+	for _, cluster := range clusters {
+		builder.WriteString(fmt.Sprintf("%s %s\n", output.Yellow.Sprintf("%8d", cluster.total), cluster.template()))
+
+		if _, unnamed := cluster.contexts[""]; !unnamed || len(cluster.contexts) > 1 {
+			builder.WriteString(fmt.Sprintf("%8s %s\n", "", cluster.contextsCount()))
+		}
+
+		for _, example := range cluster.examples {
+			builder.WriteString(output.Green.Sprintf("%8s e.g. %s\n", "", example))
+		}
+	}
+
+	output.Std("", "%s", builder.String())
+}
+
+func tokenize(message string) []string {
+	if len(message) > patternMaxTokenized {
+		message = message[:patternMaxTokenized]
+	}
+
+	return strings.Fields(maskText(message))
+}
+
+func (pc *patternCluster) similarity(tokens []string) float64 {
+	var equal int
+
+	for index, token := range pc.tokens {
+		if token == tokens[index] {
+			equal++
+		}
+	}
+
+	return float64(equal) / float64(len(tokens))
+}
+
+func (pc *patternCluster) merge(tokens []string) {
+	for index, token := range pc.tokens {
+		if token != tokens[index] {
+			pc.tokens[index] = wildcard
+		}
+	}
+}
+
+func (pc *patternCluster) template() string {
+	return strings.Join(pc.tokens, " ")
+}
+
+func (pc *patternCluster) contextsCount() string {
+	names := make([]string, 0, len(pc.contexts))
+	for name := range pc.contexts {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	counts := make([]string, len(names))
+	for index, name := range names {
+		counts[index] = fmt.Sprintf("%s: %d", output.Blue.Sprint(name), pc.contexts[name])
+	}
+
+	return strings.Join(counts, "  ")
+}
@@ -0,0 +1,72 @@
+package log
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestPatternsCollect(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		lines []string
+	}
+
+	cases := map[string]struct {
+		args args
+		want []string
+	}{
+		"masking": {
+			args{
+				lines: []string{
+					"request 3f2b8c1e-4a5d-4e6f-8a9b-0c1d2e3f4a5b from 10.0.0.1:8080 took 12ms",
+					"request 9a8b7c6d-5e4f-4a3b-2c1d-0e9f8a7b6c5d from 10.0.0.2:8080 took 1.5s",
+				},
+			},
+			[]string{"request <UUID> from <IP> took <DURATION>"},
+		},
+		"wildcard": {
+			args{
+				lines: []string{
+					`{"msg":"user alice logged in"}`,
+					`{"msg":"user bob logged in"}`,
+				},
+			},
+			[]string{"user <*> logged in"},
+		},
+		"distinct": {
+			args{
+				lines: []string{
+					"cache hit",
+					"cache miss for key 42",
+				},
+			},
+			[]string{"cache hit", "cache miss for key <NUM>"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			patterns := NewPatterns()
+			for _, line := range testCase.args.lines {
+				patterns.collect(source{}, nil, line, nil)
+			}
+
+			var got []string
+			for _, group := range patterns.groups {
+				for _, cluster := range group {
+					got = append(got, cluster.template())
+				}
+			}
+
+			sort.Strings(got)
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("collect() = %#v, want %#v", got, testCase.want)
+			}
+		})
+	}
+}
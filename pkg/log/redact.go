@@ -0,0 +1,92 @@
+package log
+
+import (
+	"regexp"
+	"strings"
+)
+
+const redacted = "<redacted>"
+
+type redaction struct {
+	pattern     *regexp.Regexp
+	replacement string
+	luhn        bool
+}
+
+// redactions are the built-in ones, applied in order
+var redactions = []redaction{
+	{pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]+`), replacement: redacted},
+	{pattern: regexp.MustCompile(`(?i)(authorization["']?\s*[:=]\s*["']?(?:bearer |basic |token )?)[^\s"',;]+`), replacement: "${1}" + redacted},
+	{pattern: regexp.MustCompile(`(?i)(\bbearer\s+)[A-Za-z0-9\-._~+/]+=*`), replacement: "${1}" + redacted},
+	{pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`), replacement: redacted},
+	{pattern: regexp.MustCompile(`(?i)(aws_secret_access_key["']?\s*[:=]\s*["']?)[A-Za-z0-9/+=]{40}`), replacement: "${1}" + redacted},
+	{pattern: regexp.MustCompile(`(?i)((?:password|passwd|pwd|secret|api_?key|access_?token)["']?\s*[:=]\s*["']?)[^\s"'&,;]+`), replacement: "${1}" + redacted},
+	{pattern: regexp.MustCompile(`(://[^:/\s@]+:)[^@/\s]+@`), replacement: "${1}" + redacted + "@"},
+	{pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), replacement: redacted},
+	{pattern: regexp.MustCompile(`\b[3-6]\d{3}[ -]?\d{4}[ -]?\d{4}[ -]?\d{3,4}\b`), replacement: redacted, luhn: true},
+}
+
+// Redactor hides secrets of log lines before printing them
+type Redactor struct {
+	redactions []redaction
+}
+
+func NewRedactor(patterns []*regexp.Regexp) *Redactor {
+	output := &Redactor{
+		redactions: append([]redaction(nil), redactions...),
+	}
+
+	for _, pattern := range patterns {
+		output.redactions = append(output.redactions, redaction{pattern: pattern, replacement: redacted})
+	}
+
+	return output
+}
+
+func (r *Redactor) Redact(text string) string {
+	for _, item := range r.redactions {
+		if !item.luhn {
+			text = item.pattern.ReplaceAllString(text, item.replacement)
+			continue
+		}
+
+		text = item.pattern.ReplaceAllStringFunc(text, func(value string) string {
+			if isLuhnValid(value) {
+				return item.replacement
+			}
+
+			return value
+		})
+	}
+
+	return text
+}
+
+func isLuhnValid(value string) bool {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+
+		return -1
+	}, value)
+
+	var sum int
+	double := false
+
+	for index := len(digits) - 1; index >= 0; index-- {
+		digit := int(digits[index] - '0')
+
+		if double {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+		double = !double
+	}
+
+	return sum%10 == 0
+}
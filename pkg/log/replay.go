@@ -0,0 +1,120 @@
+package log
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/concurrent"
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/ViBiOh/kmux/pkg/record"
+	v1 "k8s.io/api/core/v1"
+)
+
+func (s source) entry(event logEvent) record.Entry {
+	return record.Entry{
+		Type:      record.LogType,
+		Context:   s.Context,
+		Resource:  s.Resource,
+		Revision:  s.Revision,
+		Namespace: s.Namespace,
+		Pod:       s.Pod,
+		Container: s.Container,
+		Node:      s.Node,
+		File:      s.File,
+		Timestamp: event.timestamp,
+		Text:      event.text,
+	}
+}
+
+// recordLog records the line, with the resolved hints of its source if asked, for replaying it the same way
+func (l Logger) recordLog(source source, event logEvent, withHints bool) {
+	if l.recordRedactor != nil {
+		event.text = l.recordRedactor.Redact(event.text)
+	}
+
+	entry := source.entry(event)
+	if withHints {
+		entry.Hints = &record.Hints{LogFormat: l.logFormat, LevelKeys: l.levelKeys, StatusKeys: l.statusKeys}
+	}
+
+	l.recorder.Record(entry)
+}
+
+func (l Logger) recordEvent(kube client.Kube, event v1.Event) {
+	if l.recordRedactor != nil {
+		event.Message = l.recordRedactor.Redact(event.Message)
+	}
+
+	l.recorder.Record(record.Entry{Type: record.EventType, Context: kube.Name, Event: &event})
+}
+
+func sourceOf(entry record.Entry) source {
+	return source{
+		Context:   entry.Context,
+		Resource:  entry.Resource,
+		Revision:  entry.Revision,
+		Namespace: entry.Namespace,
+		Pod:       entry.Pod,
+		Container: entry.Container,
+		Node:      entry.Node,
+		File:      entry.File,
+	}
+}
+
+// Replay feeds the log lines of a recorded session through the same processing as live ones, without any cluster
+func (l Logger) Replay(ctx context.Context, reader *record.Reader, speed float64) error {
+	if reader.Header.Command != "log" {
+		return fmt.Errorf("session of `%s` is not a log one", reader.Header.Command)
+	}
+
+	l.recorder = nil
+
+	streams := make(map[source]chan logEvent)
+	outputting := concurrent.NewSimple()
+
+	defer func() {
+		for _, stream := range streams {
+			close(stream)
+		}
+
+		outputting.Wait()
+	}()
+
+	return reader.Replay(ctx, speed, func(entry record.Entry) {
+		if entry.Type == record.EventType && entry.Event != nil && l.collector == nil {
+			l.printEvent(client.Kube{Outputter: output.NewOutputter(entry.Context), Name: entry.Context}, *entry.Event)
+
+			return
+		}
+
+		if entry.Type != record.LogType {
+			return
+		}
+
+		source := sourceOf(entry)
+
+		stream, ok := streams[source]
+		if !ok {
+			stream = make(chan logEvent)
+			streams[source] = stream
+
+			sourceLogger := l.withRecordedHints(entry.Hints)
+			sourceLogger.target = source.Resource
+			sourceLogger.revision = source.Revision
+
+			kube := client.Kube{Outputter: output.NewOutputter(source.Context), Name: source.Context}
+
+			outputter := sourceLogger.logOutputter(kube, source.Pod, source.Container)
+			if len(source.File) != 0 {
+				outputter = kube.Child(l.rawOutput, output.Green.Sprintf("[%s:%s]", sourceLogger.prefix(source.Pod, source.Container), source.File))
+			}
+
+			outputting.Go(func() {
+				sourceLogger.outputEvents(sourceLogger.groupEvents(stream), outputter, source)
+			})
+		}
+
+		stream <- logEvent{timestamp: entry.Timestamp, text: entry.Text}
+	})
+}
@@ -0,0 +1,125 @@
+package log
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/record"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestRecord(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		redactor *Redactor
+		want     []string
+	}{
+		"redacted": {
+			NewRedactor(nil),
+			[]string{"log api-1 Authorization: Bearer <redacted> logfmt", "log api-1 ready", "event api-1 password=<redacted>"},
+		},
+		"show secrets": {
+			nil,
+			[]string{"log api-1 Authorization: Bearer s3cr3t logfmt", "log api-1 ready", "event api-1 password=hunter2"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "session.kmux")
+
+			recorder, err := record.New(path, "log", []string{"prod"})
+			if err != nil {
+				t.Fatalf("record.New() = %s", err)
+			}
+
+			logger := Logger{}.WithRecorder(recorder).WithRecordRedactor(testCase.redactor).WithLogFormat(LogfmtFormat)
+
+			logger.recordLog(source{Context: "prod", Pod: "api-1", Container: "api"}, logEvent{text: "Authorization: Bearer s3cr3t"}, true)
+			logger.recordLog(source{Context: "prod", Pod: "api-1", Container: "api"}, logEvent{text: "ready"}, false)
+			logger.recordEvent(client.Kube{Name: "prod"}, v1.Event{
+				InvolvedObject: v1.ObjectReference{Kind: "Pod", Name: "api-1"},
+				Message:        "password=hunter2",
+			})
+
+			if err := recorder.Close(); err != nil {
+				t.Fatalf("Close() = %s", err)
+			}
+
+			reader, err := record.Open(path)
+			if err != nil {
+				t.Fatalf("record.Open() = %s", err)
+			}
+
+			defer reader.Close()
+
+			var got []string
+
+			if err := reader.Replay(context.Background(), 0, func(entry record.Entry) {
+				switch entry.Type {
+				case record.LogType:
+					line := "log " + entry.Pod + " " + entry.Text
+					if entry.Hints != nil {
+						line += " " + entry.Hints.LogFormat
+					}
+
+					got = append(got, line)
+				case record.EventType:
+					got = append(got, "event "+entry.Event.InvolvedObject.Name+" "+entry.Event.Message)
+				}
+			}); err != nil {
+				t.Fatalf("Replay() = %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("record = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestWithRecordedHints(t *testing.T) {
+	t.Parallel()
+
+	recorded := &record.Hints{LogFormat: LogfmtFormat, LevelKeys: []string{"lvl"}, StatusKeys: []string{"code"}}
+
+	cases := map[string]struct {
+		logger Logger
+		hints  *record.Hints
+		want   record.Hints
+	}{
+		"none": {
+			Logger{}.WithLevelKeys([]string{"level"}, false),
+			nil,
+			record.Hints{LevelKeys: []string{"level"}},
+		},
+		"recorded": {
+			Logger{}.WithLevelKeys([]string{"level"}, false).WithStatusKeys([]string{"status"}, false),
+			recorded,
+			*recorded,
+		},
+		"forced by flags": {
+			Logger{}.WithLogFormat(TextFormat).WithLevelKeys([]string{"severity"}, true).WithStatusKeys([]string{"status"}, false),
+			recorded,
+			record.Hints{LogFormat: TextFormat, LevelKeys: []string{"severity"}, StatusKeys: []string{"code"}},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			logger := testCase.logger.withRecordedHints(testCase.hints)
+
+			if got := (record.Hints{LogFormat: logger.logFormat, LevelKeys: logger.levelKeys, StatusKeys: logger.statusKeys}); !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("withRecordedHints() = %+v, want %+v", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/ViBiOh/kmux/pkg/resource"
+	"github.com/ViBiOh/kmux/pkg/table"
+	"github.com/fatih/color"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// withRevision tags the logger with the revision of the pod's ReplicaSet
+func (l Logger) withRevision(ctx context.Context, kube client.Kube, pod v1.Pod) Logger {
+	if l.revisions == nil {
+		return l
+	}
+
+	l.revision = pod.Labels[appsv1.DefaultDeploymentUniqueLabelKey]
+
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind != "ReplicaSet" {
+			continue
+		}
+
+		if revision, ok := l.revisions.Load(owner.UID); ok {
+			l.revision = revision.(string)
+			break
+		}
+
+		replicaSet, err := kube.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+		if err != nil {
+			kube.Warn("get replicaset `%s`: %s", owner.Name, err)
+			break
+		}
+
+		if revision := replicaSet.Annotations[resource.RevisionAnnotation]; len(revision) != 0 {
+			l.revision = revision
+		}
+
+		l.revisions.Store(owner.UID, l.revision)
+
+		break
+	}
+
+	return l
+}
+
+type revisionKey struct {
+	context  string
+	revision string
+}
+
+type revisionStats struct {
+	total  uint
+	errors uint
+	warns  uint
+}
+
+// Comparison counts errors and warnings of each revision, for comparing the new one to the old ones
+type Comparison struct {
+	revisions map[revisionKey]*revisionStats
+	mutex     sync.Mutex
+}
+
+func NewComparison() *Comparison {
+	return &Comparison{
+		revisions: make(map[revisionKey]*revisionStats),
+	}
+}
+
+func (c *Comparison) collect(source source, outputter *color.Color, _ string, _ []string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := revisionKey{context: source.Context, revision: source.Revision}
+
+	stats, ok := c.revisions[key]
+	if !ok {
+		stats = &revisionStats{}
+		c.revisions[key] = stats
+	}
+
+	stats.total++
+
+	switch outputter {
+	case output.Red:
+		stats.errors++
+	case output.Yellow:
+		stats.warns++
+	}
+}
+
+// Print outputs the error and warn rates of revisions, per context, the new one being the last
+func (c *Comparison) Print() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	keys := make([]revisionKey, 0, len(c.revisions))
+	newest := make(map[string]string)
+
+	for key := range c.revisions {
+		keys = append(keys, key)
+
+		if current, ok := newest[key.context]; !ok || revisionIsBefore(current, key.revision) {
+			newest[key.context] = key.revision
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].context != keys[j].context {
+			return keys[i].context < keys[j].context
+		}
+
+		return revisionIsBefore(keys[i].revision, keys[j].revision)
+	})
+
+	rows := [][]table.Cell{{
+		table.NewCell("CONTEXT"),
+		table.NewCell("REVISION"),
+		table.NewCell(""),
+		table.NewCell("LINES"),
+		table.NewCell("ERROR"),
+		table.NewCell("ERROR RATE"),
+		table.NewCell("WARN"),
+		table.NewCell("WARN RATE"),
+	}}
+
+	for _, key := range keys {
+		stats := c.revisions[key]
+
+		state := table.NewCell("old")
+		if newest[key.context] == key.revision {
+			state = table.NewCellColor("new", output.Green)
+		}
+
+		rows = append(rows, []table.Cell{
+			table.NewCellColor(key.context, output.Blue),
+			table.NewCell(key.revision),
+			state,
+			table.NewCell(strconv.FormatUint(uint64(stats.total), 10)),
+			table.NewCellColor(strconv.FormatUint(uint64(stats.errors), 10), output.Red),
+			table.NewCellColor(stats.rate(stats.errors), output.Red),
+			table.NewCellColor(strconv.FormatUint(uint64(stats.warns), 10), output.Yellow),
+			table.NewCellColor(stats.rate(stats.warns), output.Yellow),
+		})
+	}
+
+	output.Std("", "%s", table.Render(rows))
+}
+
+func (rs revisionStats) rate(count uint) string {
+	if rs.total == 0 {
+		return "-"
+	}
+
+	return fmt.Sprintf("%.2f%%", float64(count)*100/float64(rs.total))
+}
+
+// revisionIsBefore compares revisions numerically, falling back to text for pod-template-hash
+func revisionIsBefore(first, second string) bool {
+	firstNumber, firstErr := strconv.Atoi(first)
+	secondNumber, secondErr := strconv.Atoi(second)
+
+	if firstErr == nil && secondErr == nil {
+		return firstNumber < secondNumber
+	}
+
+	return first < second
+}
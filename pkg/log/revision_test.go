@@ -0,0 +1,49 @@
+package log
+
+import "testing"
+
+func TestRevisionIsBefore(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		first  string
+		second string
+	}
+
+	cases := map[string]struct {
+		args args
+		want bool
+	}{
+		"numeric": {
+			args{
+				first:  "9",
+				second: "10",
+			},
+			true,
+		},
+		"numeric after": {
+			args{
+				first:  "10",
+				second: "9",
+			},
+			false,
+		},
+		"hash": {
+			args{
+				first:  "5d4c8b7f9",
+				second: "7f6b9c6d5",
+			},
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := revisionIsBefore(testCase.args.first, testCase.args.second); got != testCase.want {
+				t.Errorf("revisionIsBefore() = %t, want %t", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package log
+
+import (
+	"sync"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+type sampledWorkload struct {
+	followed   map[types.UID]struct{}
+	candidates map[types.UID]v1.Pod
+}
+
+// sampler follows a given number of pods per workload, the others being kept as candidates for replacing them
+type sampler struct {
+	workloads map[types.UID]*sampledWorkload
+	size      uint
+	mutex     sync.Mutex
+}
+
+func newSampler(size uint) *sampler {
+	return &sampler{
+		size:      size,
+		workloads: make(map[types.UID]*sampledWorkload),
+	}
+}
+
+// workloadOf returns the UID of the pod's controller, or of the pod itself if standalone
+func workloadOf(pod v1.Pod) types.UID {
+	if owner := metav1.GetControllerOf(&pod); owner != nil {
+		return owner.UID
+	}
+
+	return pod.UID
+}
+
+// follow returns true if the pod is followed, keeping it as a candidate otherwise
+func (s *sampler) follow(pod v1.Pod) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	workloadUID := workloadOf(pod)
+
+	workload, ok := s.workloads[workloadUID]
+	if !ok {
+		workload = &sampledWorkload{
+			followed:   make(map[types.UID]struct{}),
+			candidates: make(map[types.UID]v1.Pod),
+		}
+
+		s.workloads[workloadUID] = workload
+	}
+
+	if _, ok := workload.followed[pod.UID]; ok {
+		return true
+	}
+
+	if uint(len(workload.followed)) < s.size {
+		workload.followed[pod.UID] = struct{}{}
+		delete(workload.candidates, pod.UID)
+
+		return true
+	}
+
+	workload.candidates[pod.UID] = pod
+
+	return false
+}
+
+// remove forgets the pod, it returns the candidate with the highest priority if the pod was followed
+func (s *sampler) remove(pod v1.Pod) (v1.Pod, bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	workloadUID := workloadOf(pod)
+
+	workload, ok := s.workloads[workloadUID]
+	if !ok {
+		return v1.Pod{}, false
+	}
+
+	defer func() {
+		if len(workload.followed) == 0 && len(workload.candidates) == 0 {
+			delete(s.workloads, workloadUID)
+		}
+	}()
+
+	delete(workload.candidates, pod.UID)
+
+	if _, ok := workload.followed[pod.UID]; !ok {
+		return v1.Pod{}, false
+	}
+
+	delete(workload.followed, pod.UID)
+
+	var replacement v1.Pod
+	var found bool
+
+	for _, candidate := range workload.candidates {
+		if !found || priorityOf(candidate).before(priorityOf(replacement)) {
+			replacement = candidate
+			found = true
+		}
+	}
+
+	if found {
+		delete(workload.candidates, replacement.UID)
+		workload.followed[replacement.UID] = struct{}{}
+	}
+
+	return replacement, found
+}
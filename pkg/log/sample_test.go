@@ -0,0 +1,132 @@
+package log
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func sampledPod(name string, ready bool, created time.Time) v1.Pod {
+	controller := true
+
+	status := v1.ConditionFalse
+	if ready {
+		status = v1.ConditionTrue
+	}
+
+	return v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			UID:               types.UID(name),
+			CreationTimestamp: metav1.NewTime(created),
+			OwnerReferences:   []metav1.OwnerReference{{UID: "api-7d9f", Controller: &controller}},
+		},
+		Status: v1.PodStatus{
+			Conditions: []v1.PodCondition{{Type: v1.PodReady, Status: status}},
+		},
+	}
+}
+
+func TestSampler(t *testing.T) {
+	t.Parallel()
+
+	type sampleEvent struct {
+		pod     v1.Pod
+		deleted bool
+	}
+
+	now := time.Now()
+
+	first := sampledPod("first", true, now.Add(-time.Hour))
+	second := sampledPod("second", true, now.Add(-time.Minute))
+	third := sampledPod("third", true, now.Add(-time.Second))
+	unreadyFirst := sampledPod("first", false, now.Add(-time.Hour))
+
+	standalone := sampledPod("standalone", true, now)
+	standalone.OwnerReferences = nil
+
+	// a followed pod yields `follow`, a candidate `candidate`, and a deletion the name of the promoted candidate, if any
+	cases := map[string]struct {
+		size      uint
+		events    []sampleEvent
+		want      []string
+		workloads int
+	}{
+		"per workload limit": {
+			2,
+			[]sampleEvent{{pod: first}, {pod: second}, {pod: third}, {pod: standalone}},
+			[]string{"follow", "follow", "candidate", "follow"},
+			2,
+		},
+		"followed pod modified": {
+			1,
+			[]sampleEvent{{pod: first}, {pod: first}, {pod: second}},
+			[]string{"follow", "follow", "candidate"},
+			1,
+		},
+		"highest priority promoted": {
+			1,
+			[]sampleEvent{{pod: third}, {pod: first}, {pod: second}, {pod: third, deleted: true}},
+			[]string{"follow", "candidate", "candidate", "second"},
+			1,
+		},
+		"candidate modified": {
+			1,
+			[]sampleEvent{{pod: third}, {pod: first}, {pod: second}, {pod: unreadyFirst}, {pod: third, deleted: true}},
+			[]string{"follow", "candidate", "candidate", "candidate", "first"},
+			1,
+		},
+		"candidate deleted": {
+			1,
+			[]sampleEvent{{pod: first}, {pod: second}, {pod: second, deleted: true}, {pod: first, deleted: true}},
+			[]string{"follow", "candidate", "", ""},
+			0,
+		},
+		"no candidate": {
+			1,
+			[]sampleEvent{{pod: first}, {pod: first, deleted: true}, {pod: second}},
+			[]string{"follow", "", "follow"},
+			1,
+		},
+		"unknown pod deleted": {
+			1,
+			[]sampleEvent{{pod: first, deleted: true}},
+			[]string{""},
+			0,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			instance := newSampler(testCase.size)
+
+			var got []string
+
+			for _, event := range testCase.events {
+				switch {
+				case event.deleted:
+					replacement, _ := instance.remove(event.pod)
+					got = append(got, replacement.Name)
+				case instance.follow(event.pod):
+					got = append(got, "follow")
+				default:
+					got = append(got, "candidate")
+				}
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("sampler = %q, want %q", got, testCase.want)
+			}
+
+			if len(instance.workloads) != testCase.workloads {
+				t.Errorf("sampler kept %d workloads, want %d", len(instance.workloads), testCase.workloads)
+			}
+		})
+	}
+}
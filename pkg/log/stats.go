@@ -0,0 +1,280 @@
+package log
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/ViBiOh/kmux/pkg/table"
+	"github.com/fatih/color"
+)
+
+const (
+	clearScreen      = "\033[H\033[2J"
+	maxMessages      = 10000
+	maxMessageLength = 80
+	topCount         = 3
+)
+
+type statsKey struct {
+	context string
+	pod     string
+}
+
+type sourceStats struct {
+	statuses map[int]uint
+	messages map[string]uint
+	total    uint
+	previous uint
+	rate     float64
+	errors   uint
+	warns    uint
+}
+
+func newSourceStats() *sourceStats {
+	return &sourceStats{
+		statuses: make(map[int]uint),
+		messages: make(map[string]uint),
+	}
+}
+
+// Stats aggregates log lines of every context instead of printing them
+type Stats struct {
+	sources    map[statsKey]*sourceStats
+	done       chan struct{}
+	stop       chan struct{}
+	lastRender time.Time
+	mutex      sync.Mutex
+}
+
+func NewStats() *Stats {
+	return &Stats{
+		sources:    make(map[statsKey]*sourceStats),
+		lastRender: time.Now(),
+		done:       make(chan struct{}),
+		stop:       make(chan struct{}),
+	}
+}
+
+// Start renders the dashboard at given interval, the returned func stops it after a last render
+func (s *Stats) Start(interval time.Duration) func() {
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				s.render()
+			case <-s.stop:
+				s.render()
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(s.stop)
+		<-s.done
+	}
+}
+
+func (s *Stats) collect(source source, outputter *color.Color, text string, statusKeys []string) {
+	fields, isJSON := parseFields(text)
+	message := messageOf(fields, text)
+
+	var status int
+	if isJSON {
+		for _, key := range statusKeys {
+			if value, ok := lookupField(fields, key); ok {
+				if number, ok := fieldNumber(value); ok {
+					status = int(number)
+					break
+				}
+			}
+		}
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := statsKey{context: source.Context, pod: source.Pod}
+
+	stats, ok := s.sources[key]
+	if !ok {
+		stats = newSourceStats()
+		s.sources[key] = stats
+	}
+
+	stats.total++
+
+	switch outputter {
+	case output.Red:
+		stats.errors++
+	case output.Yellow:
+		stats.warns++
+	}
+
+	if status != 0 {
+		stats.statuses[status]++
+	}
+
+	if _, ok := stats.messages[message]; ok || len(stats.messages) < maxMessages {
+		stats.messages[message]++
+	}
+}
+
+func (s *Stats) render() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	elapsed := now.Sub(s.lastRender).Seconds()
+	s.lastRender = now
+
+	keys := make([]statsKey, 0, len(s.sources))
+	contexts := make(map[string]*sourceStats)
+	overall := newSourceStats()
+
+	for key, stats := range s.sources {
+		keys = append(keys, key)
+
+		stats.updateRate(elapsed)
+
+		contextStats, ok := contexts[key.context]
+		if !ok {
+			contextStats = newSourceStats()
+			contexts[key.context] = contextStats
+		}
+
+		contextStats.merge(stats)
+		overall.merge(stats)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].context != keys[j].context {
+			return keys[i].context < keys[j].context
+		}
+
+		return keys[i].pod < keys[j].pod
+	})
+
+	rows := [][]table.Cell{{
+		table.NewCell("CONTEXT"),
+		table.NewCell("POD"),
+		table.NewCell("LINES/S"),
+		table.NewCell("TOTAL"),
+		table.NewCell("ERROR"),
+		table.NewCell("WARN"),
+		table.NewCell("TOP STATUS"),
+		table.NewCell("TOP MESSAGE"),
+	}}
+
+	var previousContext string
+
+	for index, key := range keys {
+		if index == 0 || key.context != previousContext {
+			rows = append(rows, contexts[key.context].cells(table.NewCellColor(key.context, output.Blue), table.NewCellColor("*", output.Blue)))
+			previousContext = key.context
+		}
+
+		rows = append(rows, s.sources[key].cells(table.NewCell(""), table.NewCell(key.pod)))
+	}
+
+	var builder strings.Builder
+	builder.WriteString(clearScreen)
+	builder.WriteString(table.Render(rows))
+
+	builder.WriteString(output.Yellow.Sprint("\nTop repeated messages\n"))
+
+	for _, message := range topOf(overall.messages, topCount*2) {
+		builder.WriteString(fmt.Sprintf("%8d %s\n", overall.messages[message], truncate(message)))
+	}
+
+	output.Std("", "%s", builder.String())
+}
+
+// updateRate computes the lines per second since the previous update
+func (ss *sourceStats) updateRate(elapsed float64) {
+	if elapsed > 0 {
+		ss.rate = float64(ss.total-ss.previous) / elapsed
+	}
+
+	ss.previous = ss.total
+}
+
+func (ss *sourceStats) merge(other *sourceStats) {
+	ss.total += other.total
+	ss.rate += other.rate
+	ss.errors += other.errors
+	ss.warns += other.warns
+
+	for status, count := range other.statuses {
+		ss.statuses[status] += count
+	}
+
+	for message, count := range other.messages {
+		ss.messages[message] += count
+	}
+}
+
+func (ss *sourceStats) cells(context, pod table.Cell) []table.Cell {
+	statuses := make([]string, 0, topCount)
+	for _, status := range topOf(ss.statuses, topCount) {
+		statuses = append(statuses, fmt.Sprintf("%d:%d", status, ss.statuses[status]))
+	}
+
+	var message string
+	if top := topOf(ss.messages, 1); len(top) != 0 {
+		message = fmt.Sprintf("(%d) %s", ss.messages[top[0]], truncate(top[0]))
+	}
+
+	return []table.Cell{
+		context,
+		pod,
+		table.NewCell(strconv.FormatFloat(ss.rate, 'f', 1, 64)),
+		table.NewCell(strconv.FormatUint(uint64(ss.total), 10)),
+		table.NewCellColor(strconv.FormatUint(uint64(ss.errors), 10), output.Red),
+		table.NewCellColor(strconv.FormatUint(uint64(ss.warns), 10), output.Yellow),
+		table.NewCell(strings.Join(statuses, " ")),
+		table.NewCell(message),
+	}
+}
+
+func topOf[T cmp.Ordered](counts map[T]uint, size int) []T {
+	keys := make([]T, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+
+		return keys[i] < keys[j]
+	})
+
+	if len(keys) > size {
+		keys = keys[:size]
+	}
+
+	return keys
+}
+
+func truncate(message string) string {
+	message = strings.ReplaceAll(message, "\n", " ")
+
+	if runes := []rune(message); len(runes) > maxMessageLength {
+		return string(runes[:maxMessageLength-3]) + "..."
+	}
+
+	return message
+}
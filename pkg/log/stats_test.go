@@ -0,0 +1,241 @@
+package log
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/fatih/color"
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestStatsCollect(t *testing.T) {
+	t.Parallel()
+
+	type line struct {
+		outputter  *color.Color
+		text       string
+		statusKeys []string
+	}
+
+	cases := map[string]struct {
+		lines []line
+		want  sourceStats
+	}{
+		"levels": {
+			[]line{
+				{output.Red, "failed", nil},
+				{output.Red, "failed", nil},
+				{output.Yellow, "slow", nil},
+				{output.White, "done", nil},
+			},
+			sourceStats{
+				total:    4,
+				errors:   2,
+				warns:    1,
+				statuses: map[int]uint{},
+				messages: map[string]uint{"failed": 2, "slow": 1, "done": 1},
+			},
+		},
+		"source status keys": {
+			[]line{
+				{output.White, `{"msg":"served","code":200}`, []string{"code"}},
+				{output.Yellow, `{"msg":"served","code":404}`, []string{"code"}},
+				{output.White, `{"msg":"served","status":200}`, []string{"code"}},
+			},
+			sourceStats{
+				total:    3,
+				warns:    1,
+				statuses: map[int]uint{200: 1, 404: 1},
+				messages: map[string]uint{"served": 3},
+			},
+		},
+		"text status": {
+			[]line{
+				{output.White, "status 200", nil},
+			},
+			sourceStats{
+				total:    1,
+				statuses: map[int]uint{},
+				messages: map[string]uint{"status 200": 1},
+			},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			stats := NewStats()
+
+			for _, item := range testCase.lines {
+				stats.collect(source{Context: "prod", Pod: "api-1"}, item.outputter, item.text, item.statusKeys)
+			}
+
+			if got := stats.sources[statsKey{context: "prod", pod: "api-1"}]; !reflect.DeepEqual(*got, testCase.want) {
+				t.Errorf("collect() = %+v, want %+v", *got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestStatsPodHints(t *testing.T) {
+	t.Parallel()
+
+	pod := v1.Pod{}
+	pod.Annotations = map[string]string{statusKeyAnnotation: "http_code"}
+
+	stats := NewStats()
+
+	Logger{}.
+		WithCollector(stats).
+		WithStatusKeys([]string{"status"}, false).
+		withHints(client.Kube{}, pod).
+		outputLog(strings.NewReader(`{"msg":"served","http_code":503}`), output.Outputter{}, source{Context: "prod", Pod: "api-1"})
+
+	want := map[int]uint{503: 1}
+
+	if got := stats.sources[statsKey{context: "prod", pod: "api-1"}].statuses; !reflect.DeepEqual(got, want) {
+		t.Errorf("collect() = %v, want %v", got, want)
+	}
+}
+
+func TestSourceStatsMerge(t *testing.T) {
+	t.Parallel()
+
+	first := newSourceStats()
+	first.total, first.errors, first.warns, first.rate = 10, 2, 1, 1.5
+	first.statuses[200] = 8
+	first.messages["done"] = 8
+
+	second := newSourceStats()
+	second.total, second.errors, second.rate = 5, 3, 0.5
+	second.statuses[200] = 2
+	second.statuses[500] = 3
+	second.messages["done"] = 2
+	second.messages["failed"] = 3
+
+	overall := newSourceStats()
+	overall.merge(first)
+	overall.merge(second)
+
+	want := sourceStats{
+		total:    15,
+		errors:   5,
+		warns:    1,
+		rate:     2,
+		statuses: map[int]uint{200: 10, 500: 3},
+		messages: map[string]uint{"done": 10, "failed": 3},
+	}
+
+	if !reflect.DeepEqual(*overall, want) {
+		t.Errorf("merge() = %+v, want %+v", *overall, want)
+	}
+}
+
+func TestSourceStatsUpdateRate(t *testing.T) {
+	t.Parallel()
+
+	stats := newSourceStats()
+	stats.total = 30
+
+	stats.updateRate(2)
+	if stats.rate != 15 {
+		t.Errorf("updateRate() = %f, want %f", stats.rate, 15.0)
+	}
+
+	stats.total = 40
+
+	stats.updateRate(5)
+	if stats.rate != 2 {
+		t.Errorf("updateRate() = %f, want %f", stats.rate, 2.0)
+	}
+
+	stats.updateRate(0)
+	if stats.rate != 2 {
+		t.Errorf("updateRate() = %f, want %f", stats.rate, 2.0)
+	}
+}
+
+func TestTopOf(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		counts map[string]uint
+		size   int
+		want   []string
+	}{
+		"empty": {
+			nil,
+			3,
+			[]string{},
+		},
+		"ranked": {
+			map[string]uint{"done": 10, "failed": 3, "slow": 5},
+			3,
+			[]string{"done", "slow", "failed"},
+		},
+		"ties": {
+			map[string]uint{"b": 2, "a": 2, "c": 1},
+			3,
+			[]string{"a", "b", "c"},
+		},
+		"limited": {
+			map[string]uint{"done": 10, "failed": 3, "slow": 5},
+			2,
+			[]string{"done", "slow"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := topOf(testCase.counts, testCase.size); !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("topOf() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		message string
+		want    string
+	}{
+		"short": {
+			"request done",
+			"request done",
+		},
+		"multiline": {
+			"request\ndone",
+			"request done",
+		},
+		"long": {
+			strings.Repeat("a", maxMessageLength+1),
+			strings.Repeat("a", maxMessageLength-3) + "...",
+		},
+		"multibyte": {
+			strings.Repeat("é", maxMessageLength+1),
+			strings.Repeat("é", maxMessageLength-3) + "...",
+		},
+		"multibyte fitting": {
+			strings.Repeat("é", maxMessageLength),
+			strings.Repeat("é", maxMessageLength),
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := truncate(testCase.message); got != testCase.want {
+				t.Errorf("truncate() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,238 @@
+package log
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/concurrent"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// schedulerSettle is the time given to the watcher for listing existing pods before prioritizing them
+const schedulerSettle = 500 * time.Millisecond
+
+// streamKey identifies a stream of a pod's container, or of a file inside it
+type streamKey struct {
+	uid       types.UID
+	container string
+	file      string
+}
+
+// streams holds the streams of a context, started or waiting for a slot
+type streams struct {
+	scheduler *scheduler
+	running   *concurrent.Simple
+	active    sync.Map
+}
+
+func newStreams(maxStreams uint) *streams {
+	output := &streams{
+		running: concurrent.NewSimple(),
+	}
+
+	if maxStreams != 0 {
+		output.scheduler = newScheduler(maxStreams, schedulerSettle)
+	}
+
+	return output
+}
+
+// start runs the given follow stream once per key, whatever the number of pod's events received, until the pod is deleted
+func (s *streams) start(ctx context.Context, pod v1.Pod, key streamKey, stream func(context.Context)) {
+	s.run(ctx, pod, key, stream, true)
+}
+
+// dump runs the given one-shot stream once per key, finishing even if the pod is deleted meanwhile, e.g. a completed Job's pod
+func (s *streams) dump(ctx context.Context, pod v1.Pod, key streamKey, stream func(context.Context)) {
+	s.run(ctx, pod, key, stream, false)
+}
+
+func (s *streams) run(ctx context.Context, pod v1.Pod, key streamKey, stream func(context.Context), follow bool) {
+	streamCtx, streamCancel := context.WithCancel(ctx)
+
+	// only follow streams are cancelled by stop
+	var value any
+	if follow {
+		value = streamCancel
+	}
+
+	if _, loaded := s.active.LoadOrStore(key, value); loaded {
+		streamCancel()
+		return
+	}
+
+	s.running.Go(func() {
+		defer streamCancel()
+
+		if s.scheduler != nil {
+			if !s.scheduler.acquire(streamCtx, priorityOf(pod)) {
+				return
+			}
+
+			defer s.scheduler.release()
+		}
+
+		stream(streamCtx)
+	})
+}
+
+// stop cancels every follow stream of the given pod, started or waiting, dumps being left to finish
+func (s *streams) stop(uid types.UID) {
+	s.active.Range(func(key, value any) bool {
+		if key.(streamKey).uid == uid {
+			if cancel, ok := value.(context.CancelFunc); ok {
+				cancel()
+			}
+
+			s.active.Delete(key)
+		}
+
+		return true
+	})
+}
+
+func (s *streams) wait() {
+	s.running.Wait()
+}
+
+// streamPriority orders pods needing attention first: not ready or restarting ones, then the newest
+type streamPriority struct {
+	created time.Time
+	urgent  bool
+}
+
+func priorityOf(pod v1.Pod) streamPriority {
+	priority := streamPriority{
+		created: pod.CreationTimestamp.Time,
+		urgent:  true,
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == v1.PodReady {
+			priority.urgent = condition.Status != v1.ConditionTrue
+		}
+	}
+
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		if status.RestartCount != 0 {
+			priority.urgent = true
+		}
+	}
+
+	return priority
+}
+
+func (sp streamPriority) before(other streamPriority) bool {
+	if sp.urgent != other.urgent {
+		return sp.urgent
+	}
+
+	return sp.created.After(other.created)
+}
+
+type schedulerWaiter struct {
+	granted  chan struct{}
+	priority streamPriority
+}
+
+// scheduler limits the number of concurrent streams, granting slots by priority
+type scheduler struct {
+	waiters []*schedulerWaiter
+	max     uint
+	running uint
+	mutex   sync.Mutex
+	settled bool
+}
+
+func newScheduler(maxStreams uint, settle time.Duration) *scheduler {
+	output := &scheduler{
+		max: maxStreams,
+	}
+
+	time.AfterFunc(settle, output.settle)
+
+	return output
+}
+
+// settle starts granting slots, existing pods having been listed
+func (s *scheduler) settle() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.settled = true
+	s.schedule()
+}
+
+// acquire waits for a slot, it returns false if the context is done before
+func (s *scheduler) acquire(ctx context.Context, priority streamPriority) bool {
+	return s.wait(ctx, s.enqueue(priority))
+}
+
+func (s *scheduler) enqueue(priority streamPriority) *schedulerWaiter {
+	waiter := &schedulerWaiter{
+		priority: priority,
+		granted:  make(chan struct{}),
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.waiters = append(s.waiters, waiter)
+	s.schedule()
+
+	return waiter
+}
+
+func (s *scheduler) wait(ctx context.Context, waiter *schedulerWaiter) bool {
+	select {
+	case <-waiter.granted:
+		return true
+
+	case <-ctx.Done():
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+
+		if index := slices.Index(s.waiters, waiter); index != -1 {
+			s.waiters = slices.Delete(s.waiters, index, index+1)
+			return false
+		}
+
+		// slot has been granted concurrently
+		s.running--
+		s.schedule()
+
+		return false
+	}
+}
+
+func (s *scheduler) release() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.running--
+	s.schedule()
+}
+
+// schedule grants slots to the waiters with the highest priority, lock has to be held
+func (s *scheduler) schedule() {
+	if !s.settled {
+		return
+	}
+
+	for s.running < s.max && len(s.waiters) != 0 {
+		best := 0
+
+		for index, waiter := range s.waiters {
+			if waiter.priority.before(s.waiters[best].priority) {
+				best = index
+			}
+		}
+
+		close(s.waiters[best].granted)
+		s.waiters = slices.Delete(s.waiters, best, best+1)
+		s.running++
+	}
+}
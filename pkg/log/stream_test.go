@@ -0,0 +1,113 @@
+package log
+
+import (
+	"context"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestStreamsStart(t *testing.T) {
+	t.Parallel()
+
+	var count atomic.Int32
+
+	streams := newStreams(0)
+	started := make(chan struct{})
+
+	key := streamKey{uid: "1234", container: "api"}
+	stream := func(ctx context.Context) {
+		if count.Add(1) == 1 {
+			close(started)
+		}
+
+		<-ctx.Done()
+	}
+
+	for range 3 {
+		streams.start(context.Background(), v1.Pod{}, key, stream)
+	}
+
+	<-started
+	streams.stop(key.uid)
+	streams.wait()
+
+	if got := count.Load(); got != 1 {
+		t.Errorf("start() = %d, want %d", got, 1)
+	}
+
+	if _, ok := streams.active.Load(key); ok {
+		t.Error("stop() kept the stream")
+	}
+}
+
+func TestStreamsDump(t *testing.T) {
+	t.Parallel()
+
+	streams := newStreams(0)
+	started := make(chan struct{})
+	resume := make(chan struct{})
+
+	var err error
+
+	key := streamKey{uid: "1234", container: "batch"}
+	streams.dump(context.Background(), v1.Pod{}, key, func(ctx context.Context) {
+		close(started)
+		<-resume
+		err = ctx.Err()
+	})
+
+	<-started
+	streams.stop(key.uid)
+	close(resume)
+	streams.wait()
+
+	if err != nil {
+		t.Errorf("dump() = %s, want no cancellation", err)
+	}
+
+	if _, ok := streams.active.Load(key); ok {
+		t.Error("stop() kept the stream")
+	}
+}
+
+func TestScheduler(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	priorities := map[string]streamPriority{
+		"old":    {created: now.Add(-time.Hour)},
+		"new":    {created: now},
+		"urgent": {created: now.Add(-2 * time.Hour), urgent: true},
+	}
+
+	// every stream is waiting before the scheduler settles
+	scheduler := newScheduler(1, time.Hour)
+	order := make(chan string, len(priorities))
+
+	for name, priority := range priorities {
+		waiter := scheduler.enqueue(priority)
+
+		go func() {
+			if scheduler.wait(context.Background(), waiter) {
+				order <- name
+				scheduler.release()
+			}
+		}()
+	}
+
+	scheduler.settle()
+
+	var got []string
+	for range priorities {
+		got = append(got, <-order)
+	}
+
+	if want := []string{"urgent", "new", "old"}; !slices.Equal(got, want) {
+		t.Errorf("scheduler = %v, want %v", got, want)
+	}
+}
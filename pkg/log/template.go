@@ -0,0 +1,172 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Renderer transforms a log line before its coloring, it returns the line as-is if it can't render it
+type Renderer func(string) string
+
+// missingField is the value of a field missing in the log, empty for conditions and functions, but printed as a marker
+// for removing it with its leading whitespace, e.g. `info x` instead of `info  x` for `{{.level}} {{.time}} {{.msg}}`
+type missingField string
+
+const missingMarker = "\x00"
+
+var missingSpaces = regexp.MustCompile(`[ \t]*` + missingMarker)
+
+func (missingField) String() string {
+	return missingMarker
+}
+
+func NewTemplateRenderer(content string) (Renderer, error) {
+	tmpl, err := template.New("log").Funcs(template.FuncMap{
+		"json": toJSON,
+	}).Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("parse template: %w", err)
+	}
+
+	return func(text string) string {
+		fields, ok := parseFields(text)
+		if !ok {
+			return text
+		}
+
+		// missing keys of a map are printed `<no value>`, whatever the `missingkey` option is
+		fillMissing(tmpl.Root, fields)
+
+		var builder strings.Builder
+		if err := tmpl.Execute(&builder, fields); err != nil {
+			return text
+		}
+
+		return strings.TrimSpace(missingSpaces.ReplaceAllString(builder.String(), ""))
+	}, nil
+}
+
+// fillMissing sets the fields used by the template, and missing or null in the log, to an empty missingField
+func fillMissing(node parse.Node, fields map[string]any) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+
+		for _, child := range node.Nodes {
+			fillMissing(child, fields)
+		}
+
+	case *parse.ActionNode:
+		fillMissing(node.Pipe, fields)
+
+	case *parse.IfNode:
+		fillMissingBranch(node.BranchNode, fields)
+
+	case *parse.RangeNode:
+		fillMissingBranch(node.BranchNode, fields)
+
+	case *parse.WithNode:
+		fillMissingBranch(node.BranchNode, fields)
+
+	case *parse.TemplateNode:
+		fillMissing(node.Pipe, fields)
+
+	case *parse.PipeNode:
+		if node == nil {
+			return
+		}
+
+		for _, command := range node.Cmds {
+			for _, arg := range command.Args {
+				fillMissing(arg, fields)
+			}
+		}
+
+	case *parse.FieldNode:
+		fillPath(fields, node.Ident)
+	}
+}
+
+func fillMissingBranch(node parse.BranchNode, fields map[string]any) {
+	fillMissing(node.Pipe, fields)
+	fillMissing(node.List, fields)
+	fillMissing(node.ElseList, fields)
+}
+
+func fillPath(fields map[string]any, path []string) {
+	current := fields
+
+	for index, name := range path {
+		value := current[name]
+
+		if index == len(path)-1 {
+			if value == nil {
+				current[name] = missingField("")
+			}
+
+			return
+		}
+
+		if value == nil || value == "" || value == missingField("") {
+			next := make(map[string]any)
+			current[name] = next
+			current = next
+
+			continue
+		}
+
+		next, ok := value.(map[string]any)
+		if !ok {
+			return
+		}
+
+		current = next
+	}
+}
+
+func NewFieldsRenderer(keys []string) Renderer {
+	return func(text string) string {
+		fields, ok := parseFields(text)
+		if !ok {
+			return text
+		}
+
+		values := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			if value, ok := lookupField(fields, key); ok {
+				values = append(values, fieldText(value))
+			}
+		}
+
+		if len(values) == 0 {
+			return text
+		}
+
+		return strings.Join(values, " ")
+	}
+}
+
+func fieldText(value any) string {
+	switch value.(type) {
+	case map[string]any, []any:
+		return toJSON(value)
+	default:
+		return fieldString(value)
+	}
+}
+
+func toJSON(value any) string {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+
+	return string(payload)
+}
@@ -0,0 +1,184 @@
+package log
+
+import "testing"
+
+func TestTemplateRenderer(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		template string
+		text     string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"simple": {
+			args{
+				template: "{{.level}} {{.msg}}",
+				text:     `{"level":"info","msg":"started"}`,
+			},
+			"info started",
+		},
+		"missing key": {
+			args{
+				template: "{{.time}} {{.level}} {{.msg}}",
+				text:     `{"level":"info","msg":"started"}`,
+			},
+			"info started",
+		},
+		"missing middle key": {
+			args{
+				template: "{{.level}} {{.time}} {{.msg}}",
+				text:     `{"level":"info","msg":"started"}`,
+			},
+			"info started",
+		},
+		"several missing keys": {
+			args{
+				template: "{{.level}} {{.time}}\t{{.caller}} | {{.msg}}",
+				text:     `{"level":"info","msg":"started"}`,
+			},
+			"info | started",
+		},
+		"spaces in value": {
+			args{
+				template: "{{.level}} {{.msg}}",
+				text:     `{"level":"info","msg":"key  value"}`,
+			},
+			"info key  value",
+		},
+		"missing key in function": {
+			args{
+				template: "{{.msg}} {{json .error}}",
+				text:     `{"msg":"started"}`,
+			},
+			`started ""`,
+		},
+		"missing nested key": {
+			args{
+				template: "{{.msg}} {{.http.status}}",
+				text:     `{"msg":"started"}`,
+			},
+			"started",
+		},
+		"null value": {
+			args{
+				template: "{{.msg}} {{.error}}",
+				text:     `{"msg":"started","error":null}`,
+			},
+			"started",
+		},
+		"no value in content": {
+			args{
+				template: "{{.msg}}",
+				text:     `{"msg":"field is <no value>"}`,
+			},
+			"field is <no value>",
+		},
+		"branch": {
+			args{
+				template: "{{.msg}}{{if .error}} error={{.error}}{{end}}",
+				text:     `{"msg":"started"}`,
+			},
+			"started",
+		},
+		"json": {
+			args{
+				template: "{{.msg}} {{json .context}}",
+				text:     `{"msg":"started","context":{"id":1}}`,
+			},
+			`started {"id":1}`,
+		},
+		"raw text": {
+			args{
+				template: "{{.msg}}",
+				text:     "started",
+			},
+			"started",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			renderer, err := NewTemplateRenderer(testCase.args.template)
+			if err != nil {
+				t.Fatalf("NewTemplateRenderer() = %s", err)
+			}
+
+			if got := renderer(testCase.args.text); got != testCase.want {
+				t.Errorf("NewTemplateRenderer() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestFieldsRenderer(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		keys []string
+		text string
+	}
+
+	cases := map[string]struct {
+		args args
+		want string
+	}{
+		"simple": {
+			args{
+				keys: []string{"level", "msg"},
+				text: `{"level":"info","msg":"started"}`,
+			},
+			"info started",
+		},
+		"nested and case insensitive": {
+			args{
+				keys: []string{"msg", "HTTP.status"},
+				text: `{"msg":"served","http":{"status":200}}`,
+			},
+			"served 200",
+		},
+		"object": {
+			args{
+				keys: []string{"context"},
+				text: `{"context":{"id":1}}`,
+			},
+			`{"id":1}`,
+		},
+		"some missing": {
+			args{
+				keys: []string{"msg", "trace_id"},
+				text: `{"msg":"started"}`,
+			},
+			"started",
+		},
+		"all missing": {
+			args{
+				keys: []string{"trace_id"},
+				text: `{"msg":"started"}`,
+			},
+			`{"msg":"started"}`,
+		},
+		"raw text": {
+			args{
+				keys: []string{"msg"},
+				text: "started",
+			},
+			"started",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := NewFieldsRenderer(testCase.args.keys)(testCase.args.text); got != testCase.want {
+				t.Errorf("NewFieldsRenderer() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// Trigger ends the streaming when log lines match, for waiting on a release or failing a pipeline
+type Trigger struct {
+	err      error
+	cancel   context.CancelFunc
+	matched  map[string]struct{}
+	until    []*regexp.Regexp
+	fail     []*regexp.Regexp
+	expected uint
+	mutex    sync.Mutex
+	byPod    bool
+	done     bool
+}
+
+// NewTrigger creates a trigger cancelling the streaming when `until` regexps have matched in every one of `contexts`,
+// or in `pods` distinct pods if not zero, or when a `fail` regexp matches
+func NewTrigger(until, fail []*regexp.Regexp, contexts int, pods uint, cancel context.CancelFunc) *Trigger {
+	trigger := &Trigger{
+		until:    until,
+		fail:     fail,
+		cancel:   cancel,
+		matched:  make(map[string]struct{}),
+		expected: uint(contexts),
+	}
+
+	if pods != 0 {
+		trigger.expected = pods
+		trigger.byPod = true
+	}
+
+	return trigger
+}
+
+// check matches the raw line, the printed one, e.g. redacted, being the one reported
+func (t *Trigger) check(source source, raw, printed string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.done {
+		return
+	}
+
+	if grepMatch(t.fail, raw) {
+		t.err = fmt.Errorf("failure matched in `%s`: %s", source, printed)
+		t.stop()
+
+		return
+	}
+
+	if !grepMatch(t.until, raw) {
+		return
+	}
+
+	key := source.Context
+	if t.byPod {
+		key += "/" + source.Namespace + "/" + source.Pod
+	}
+
+	t.matched[key] = struct{}{}
+
+	if uint(len(t.matched)) >= t.expected {
+		t.stop()
+	}
+}
+
+func (t *Trigger) stop() {
+	t.done = true
+	t.cancel()
+}
+
+// Err returns the reason of the failure, if any, once streaming has ended with the given context's error
+func (t *Trigger) Err(ctxErr error) error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.err != nil {
+		return t.err
+	}
+
+	if t.done || len(t.until) == 0 {
+		return nil
+	}
+
+	if errors.Is(ctxErr, context.DeadlineExceeded) {
+		return fmt.Errorf("timeout, until match found %d/%d times", len(t.matched), t.expected)
+	}
+
+	return fmt.Errorf("logs ended, until match found %d/%d times", len(t.matched), t.expected)
+}
@@ -0,0 +1,143 @@
+package log
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+)
+
+func TestTrigger(t *testing.T) {
+	t.Parallel()
+
+	type line struct {
+		source source
+		text   string
+	}
+
+	type args struct {
+		lines    []line
+		contexts int
+		pods     uint
+	}
+
+	ready := []*regexp.Regexp{regexp.MustCompile("ready")}
+	panics := []*regexp.Regexp{regexp.MustCompile("panic")}
+
+	cases := map[string]struct {
+		args       args
+		wantCancel bool
+		wantErr    bool
+	}{
+		"not every context": {
+			args{
+				contexts: 2,
+				lines: []line{
+					{source{Context: "prod", Pod: "api-1"}, "ready"},
+					{source{Context: "prod", Pod: "api-2"}, "ready"},
+				},
+			},
+			false,
+			true,
+		},
+		"every context": {
+			args{
+				contexts: 2,
+				lines: []line{
+					{source{Context: "prod", Pod: "api-1"}, "ready"},
+					{source{Context: "staging", Pod: "api-1"}, "ready"},
+				},
+			},
+			true,
+			false,
+		},
+		"pods": {
+			args{
+				contexts: 1,
+				pods:     2,
+				lines: []line{
+					{source{Context: "prod", Pod: "api-1"}, "ready"},
+					{source{Context: "prod", Pod: "api-2"}, "ready"},
+				},
+			},
+			true,
+			false,
+		},
+		"fail": {
+			args{
+				contexts: 1,
+				lines: []line{
+					{source{Context: "prod", Pod: "api-1"}, "panic: nil map"},
+					{source{Context: "prod", Pod: "api-1"}, "ready"},
+				},
+			},
+			true,
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var cancelled bool
+
+			trigger := NewTrigger(ready, panics, testCase.args.contexts, testCase.args.pods, func() { cancelled = true })
+
+			for _, line := range testCase.args.lines {
+				trigger.check(line.source, line.text, line.text)
+			}
+
+			err := trigger.Err(context.Canceled)
+
+			if cancelled != testCase.wantCancel || (err != nil) != testCase.wantErr {
+				t.Errorf("Trigger = (%t, %v), want (%t, %t)", cancelled, err, testCase.wantCancel, testCase.wantErr)
+			}
+		})
+	}
+}
+
+// TestTriggerRawLine is not parallel, printed lines being captured
+func TestTriggerRawLine(t *testing.T) {
+	cases := map[string]struct {
+		format string
+		fail   string
+	}{
+		"redacted secret": {
+			"",
+			"password=hunter2",
+		},
+		"logfmt": {
+			LogfmtFormat,
+			`level=error msg=`,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			var cancelled bool
+
+			trigger := NewTrigger(nil, []*regexp.Regexp{regexp.MustCompile(testCase.fail)}, 1, 0, func() { cancelled = true })
+
+			captureLines(func() {
+				Logger{}.
+					WithLogFormat(testCase.format).
+					WithRedactor(NewRedactor(nil)).
+					WithTrigger(trigger).
+					WithRawOutput(true).
+					outputLog(strings.NewReader(`level=error msg=boom password=hunter2`), output.NewOutputter(""), source{Context: "prod"})
+			})
+
+			err := trigger.Err(context.Canceled)
+			if !cancelled || err == nil {
+				t.Fatalf("Trigger = (%t, %v), want (true, error)", cancelled, err)
+			}
+
+			if strings.Contains(err.Error(), "hunter2") {
+				t.Errorf("Err() = `%s`, want redacted line", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,672 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+	"github.com/fatih/color"
+	"golang.org/x/term"
+)
+
+const (
+	tuiBufferSize    = 10000
+	tuiMaxStatuses   = 100
+	tuiRenderEvery   = 100 * time.Millisecond
+	tuiEnterScreen   = "\033[?1049h\033[?25l"
+	tuiLeaveScreen   = "\033[?25h\033[?1049l"
+	tuiClearLine     = "\033[2K"
+	tuiCursorHome    = "\033[H"
+	tuiClearToBottom = "\033[J"
+)
+
+type tuiMode int
+
+const (
+	tuiNormal tuiMode = iota
+	tuiSearch
+	tuiGrep
+	tuiSources
+)
+
+var (
+	tuiColorFilters = []*color.Color{nil, output.Red, output.Yellow, output.Green}
+	ansiEscape      = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+type tuiLine struct {
+	outputter *color.Color
+	source    *tuiSource
+	text      string
+}
+
+type tuiSource struct {
+	key       string
+	ancestors []string
+}
+
+// TUI is a full-screen log browser, fed as a collector
+type TUI struct {
+	colorFilter *color.Color
+	search      *regexp.Regexp
+	cancel      context.CancelFunc
+	sources     map[string]*tuiSource
+	disabled    map[string]bool
+	done        chan struct{}
+	stop        chan struct{}
+	focus       string
+	input       string
+	pending     string
+	grep        []*regexp.Regexp
+	lines       []tuiLine
+	statuses    []string
+	dropped     int
+	pausedAt    int
+	offset      int
+	cursor      int
+	mode        tuiMode
+	mutex       sync.Mutex
+	invert      bool
+	paused      bool
+	dirty       bool
+}
+
+func NewTUI(grep []*regexp.Regexp, invert bool, colorFilter *color.Color) *TUI {
+	return &TUI{
+		grep:        grep,
+		invert:      invert,
+		colorFilter: colorFilter,
+		sources:     make(map[string]*tuiSource),
+		disabled:    make(map[string]bool),
+		done:        make(chan struct{}),
+		stop:        make(chan struct{}),
+		dirty:       true,
+	}
+}
+
+func (t *TUI) collect(source source, outputter *color.Color, text string, _ []string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	key := source.String()
+
+	item, ok := t.sources[key]
+	if !ok {
+		item = &tuiSource{key: key}
+
+		var ancestors []string
+		if len(source.Context) != 0 {
+			ancestors = append(ancestors, source.Context)
+		}
+
+		ancestors = append(ancestors, strings.Join(append(slices.Clone(ancestors), source.Pod), "/"), key)
+		item.ancestors = ancestors
+
+		t.sources[key] = item
+	}
+
+	t.lines = append(t.lines, tuiLine{source: item, outputter: outputter, text: strings.ReplaceAll(text, "\n", " ↵ ")})
+
+	// trimming by batch for not moving the buffer on every line
+	if len(t.lines) > tuiBufferSize+tuiBufferSize/10 {
+		trimmed := len(t.lines) - tuiBufferSize
+		t.lines = slices.Delete(t.lines, 0, trimmed)
+		t.dropped += trimmed
+	}
+
+	t.dirty = true
+}
+
+// tuiStatus receives the outputs printed while the TUI is active, e.g. stream errors, for showing them on a status line
+// instead of corrupting the screen
+type tuiStatus struct {
+	tui *TUI
+}
+
+func (s tuiStatus) Write(payload []byte) (int, error) {
+	s.tui.mutex.Lock()
+	defer s.tui.mutex.Unlock()
+
+	s.tui.pending += string(payload)
+
+	// the printer writes the prefix and the line separately
+	index := strings.LastIndexByte(s.tui.pending, '\n')
+	if index == -1 {
+		return len(payload), nil
+	}
+
+	s.tui.statuses = append(s.tui.statuses, strings.Split(s.tui.pending[:index], "\n")...)
+	s.tui.statuses = s.tui.statuses[max(len(s.tui.statuses)-tuiMaxStatuses, 0):]
+	s.tui.pending = s.tui.pending[index+1:]
+	s.tui.dirty = true
+
+	return len(payload), nil
+}
+
+// status returns the last printed output, without its colors
+func (t *TUI) status() string {
+	if len(t.statuses) == 0 {
+		return ""
+	}
+
+	return ansiEscape.ReplaceAllString(t.statuses[len(t.statuses)-1], "")
+}
+
+// Start switches the terminal to a full-screen view, the cancel func being called when the user quits. Outputs printed
+// meanwhile are shown on a status line, then printed once the terminal is restored by the returned func.
+func (t *TUI) Start(cancel context.CancelFunc) (func(), error) {
+	inFd, outFd := int(os.Stdin.Fd()), int(os.Stdout.Fd())
+
+	if !term.IsTerminal(inFd) || !term.IsTerminal(outFd) {
+		return nil, errors.New("stdin and stdout have to be a terminal")
+	}
+
+	state, err := term.MakeRaw(inFd)
+	if err != nil {
+		return nil, fmt.Errorf("make raw terminal: %w", err)
+	}
+
+	t.cancel = cancel
+
+	_, _ = fmt.Fprint(os.Stdout, tuiEnterScreen)
+
+	status := tuiStatus{tui: t}
+	output.Redirect(status, status)
+
+	go t.readKeys()
+
+	go func() {
+		defer close(t.done)
+
+		ticker := time.NewTicker(tuiRenderEvery)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				t.render(outFd)
+			case <-t.stop:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(t.stop)
+		<-t.done
+
+		output.Flush()
+		output.Redirect(os.Stdout, os.Stderr)
+
+		_, _ = fmt.Fprint(os.Stdout, tuiLeaveScreen)
+		_ = term.Restore(inFd, state)
+
+		t.mutex.Lock()
+		defer t.mutex.Unlock()
+
+		for _, status := range t.statuses {
+			output.Info("", "%s", status)
+		}
+	}, nil
+}
+
+func (t *TUI) readKeys() {
+	buffer := make([]byte, 32)
+
+	for {
+		size, err := os.Stdin.Read(buffer)
+		if err != nil {
+			return
+		}
+
+		if t.handleKey(string(buffer[:size])) {
+			t.cancel()
+			return
+		}
+	}
+}
+
+// handleKey applies the key, it returns true if the user quits
+func (t *TUI) handleKey(key string) bool {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.dirty = true
+
+	switch t.mode {
+	case tuiSearch, tuiGrep:
+		t.handlePrompt(key)
+		return false
+
+	case tuiSources:
+		t.handleSources(key)
+		return false
+	}
+
+	switch key {
+	case "q", "\x03":
+		return true
+
+	case " ":
+		t.setPaused(!t.paused)
+
+	case "k", "\x1b[A":
+		t.setPaused(true)
+		t.offset++
+
+	case "j", "\x1b[B":
+		t.offset = max(t.offset-1, 0)
+
+	case "\x1b[5~":
+		t.setPaused(true)
+		t.offset += t.bodyHeight()
+
+	case "\x1b[6~":
+		t.offset = max(t.offset-t.bodyHeight(), 0)
+
+	case "G", "\x1b[F", "\x1b[4~":
+		t.setPaused(false)
+		t.offset = 0
+
+	case "/":
+		t.mode = tuiSearch
+		t.input = ""
+
+	case "f":
+		t.mode = tuiGrep
+		t.input = ""
+
+	case "n":
+		t.jumpToMatch(true)
+
+	case "N":
+		t.jumpToMatch(false)
+
+	case "c":
+		index := slices.Index(tuiColorFilters, t.colorFilter)
+		t.colorFilter = tuiColorFilters[(index+1)%len(tuiColorFilters)]
+
+	case "s":
+		t.mode = tuiSources
+		t.cursor = 0
+
+	case "\t":
+		t.cycleFocus()
+
+	case "\x1b":
+		t.search = nil
+	}
+
+	return false
+}
+
+// setPaused freezes the view, for scrolling back without being moved by new lines
+func (t *TUI) setPaused(paused bool) {
+	if paused && !t.paused {
+		t.pausedAt = t.dropped + len(t.lines)
+	}
+
+	t.paused = paused
+}
+
+func (t *TUI) handlePrompt(key string) {
+	switch key {
+	case "\x1b":
+		if t.mode == tuiSearch {
+			t.search = nil
+		}
+
+		t.mode = tuiNormal
+
+		return
+
+	case "\r", "\n":
+		if t.mode == tuiGrep {
+			t.grep = nil
+
+			if len(t.input) != 0 {
+				if grep, err := regexp.Compile(t.input); err == nil {
+					t.grep = []*regexp.Regexp{grep}
+				}
+			}
+
+			t.offset = 0
+		}
+
+		t.mode = tuiNormal
+
+		return
+
+	case "\x7f", "\b":
+		if _, size := utf8.DecodeLastRuneInString(t.input); size > 0 {
+			t.input = t.input[:len(t.input)-size]
+		}
+
+	default:
+		if strings.HasPrefix(key, "\x1b") || key < " " {
+			return
+		}
+
+		t.input += key
+	}
+
+	if t.mode == tuiSearch {
+		t.search = nil
+
+		if search, err := regexp.Compile(t.input); err == nil && len(t.input) != 0 {
+			t.search = search
+			t.offset = -1
+			t.jumpToMatch(true)
+		}
+	}
+}
+
+func (t *TUI) handleSources(key string) {
+	entries := t.sourceEntries()
+
+	switch key {
+	case "k", "\x1b[A":
+		t.cursor = max(t.cursor-1, 0)
+
+	case "j", "\x1b[B":
+		t.cursor = min(t.cursor+1, len(entries)-1)
+
+	case " ":
+		if t.cursor < len(entries) {
+			path := entries[t.cursor]
+			t.disabled[path] = !t.disabled[path]
+		}
+
+	case "\x1b", "\r", "s", "q":
+		t.mode = tuiNormal
+	}
+}
+
+// sourceEntries lists contexts, pods and containers, each one followed by its children
+func (t *TUI) sourceEntries() []string {
+	var entries []string
+	seen := make(map[string]bool)
+
+	for _, key := range t.sortedSources() {
+		for _, ancestor := range t.sources[key].ancestors {
+			if !seen[ancestor] {
+				seen[ancestor] = true
+				entries = append(entries, ancestor)
+			}
+		}
+	}
+
+	return entries
+}
+
+func (t *TUI) sortedSources() []string {
+	keys := make([]string, 0, len(t.sources))
+	for key := range t.sources {
+		keys = append(keys, key)
+	}
+
+	slices.Sort(keys)
+
+	return keys
+}
+
+func (t *TUI) cycleFocus() {
+	keys := t.sortedSources()
+
+	index := slices.Index(keys, t.focus)
+	if index+1 < len(keys) {
+		t.focus = keys[index+1]
+	} else {
+		t.focus = ""
+	}
+
+	t.offset = 0
+}
+
+func (t *TUI) isVisible(line tuiLine) bool {
+	if len(t.focus) != 0 && line.source.key != t.focus {
+		return false
+	}
+
+	for _, ancestor := range line.source.ancestors {
+		if t.disabled[ancestor] {
+			return false
+		}
+	}
+
+	if colorIsGreater(line.outputter, t.colorFilter) {
+		return false
+	}
+
+	return len(t.grep) == 0 || grepMatch(t.grep, line.text) != t.invert
+}
+
+func (t *TUI) visibleLines() []tuiLine {
+	end := len(t.lines)
+	if t.paused {
+		end = max(min(t.pausedAt-t.dropped, end), 0)
+	}
+
+	var visible []tuiLine
+
+	for _, line := range t.lines[:end] {
+		if t.isVisible(line) {
+			visible = append(visible, line)
+		}
+	}
+
+	return visible
+}
+
+// jumpToMatch scrolls to the previous (older) or next search's match from the bottom of the view
+func (t *TUI) jumpToMatch(older bool) {
+	if t.search == nil {
+		return
+	}
+
+	visible := t.visibleLines()
+	bottom := len(visible) - 1 - t.offset
+
+	step := 1
+	if older {
+		step = -1
+	}
+
+	for index := bottom + step; index >= 0 && index < len(visible); index += step {
+		if t.search.MatchString(visible[index].text) {
+			t.offset = len(visible) - 1 - index
+			return
+		}
+	}
+
+	t.offset = max(t.offset, 0)
+}
+
+func (t *TUI) bodyHeight() int {
+	_, height, err := term.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		return 1
+	}
+
+	return t.bodyHeightOf(height)
+}
+
+// bodyHeightOf returns the number of rows for lines, the header, the footer and the status line, if any, taking the rest
+func (t *TUI) bodyHeightOf(height int) int {
+	if len(t.statuses) != 0 {
+		height--
+	}
+
+	return max(height-2, 1)
+}
+
+func (t *TUI) render(fd int) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if !t.dirty {
+		return
+	}
+
+	t.dirty = false
+
+	width, height, err := term.GetSize(fd)
+	if err != nil {
+		return
+	}
+
+	bodyHeight := t.bodyHeightOf(height)
+
+	var builder strings.Builder
+	builder.WriteString(tuiCursorHome)
+
+	t.writeRow(&builder, output.Cyan.Sprint(truncateWidth(t.header(), width)))
+
+	if t.mode == tuiSources {
+		t.renderSources(&builder, bodyHeight, width)
+	} else {
+		t.renderLines(&builder, bodyHeight, width)
+	}
+
+	if status := t.status(); len(status) != 0 {
+		t.writeRow(&builder, output.Red.Sprint(truncateWidth(status, width)))
+	}
+
+	builder.WriteString(tuiClearLine)
+	builder.WriteString(output.Cyan.Sprint(truncateWidth(t.footer(), width)))
+	builder.WriteString(tuiClearToBottom)
+
+	_, _ = fmt.Fprint(os.Stdout, builder.String())
+}
+
+func (t *TUI) writeRow(builder *strings.Builder, content string) {
+	builder.WriteString(tuiClearLine)
+	builder.WriteString(content)
+	builder.WriteString("\r\n")
+}
+
+func (t *TUI) renderLines(builder *strings.Builder, bodyHeight, width int) {
+	visible := t.visibleLines()
+
+	t.offset = max(min(t.offset, len(visible)-bodyHeight), 0)
+
+	end := len(visible) - t.offset
+	start := max(end-bodyHeight, 0)
+
+	for index := 0; index < bodyHeight; index++ {
+		if start+index >= end {
+			t.writeRow(builder, "")
+			continue
+		}
+
+		line := visible[start+index]
+
+		prefix := "[" + line.source.key + "] "
+		if len(t.focus) != 0 {
+			prefix = ""
+		}
+
+		text := truncateWidth(line.text, width-utf8.RuneCountInString(prefix))
+
+		switch {
+		case t.search != nil:
+			text = FormatGrep(text, []*regexp.Regexp{t.search}, line.outputter)
+		case len(t.grep) != 0 && !t.invert:
+			text = FormatGrep(text, t.grep, line.outputter)
+		default:
+			text = Format(text, line.outputter)
+		}
+
+		t.writeRow(builder, output.Green.Sprint(prefix)+text)
+	}
+}
+
+func (t *TUI) renderSources(builder *strings.Builder, bodyHeight, width int) {
+	entries := t.sourceEntries()
+	start := max(t.cursor-bodyHeight+1, 0)
+
+	for index := 0; index < bodyHeight; index++ {
+		if start+index >= len(entries) {
+			t.writeRow(builder, "")
+			continue
+		}
+
+		path := entries[start+index]
+
+		check := "[x]"
+		if t.disabled[path] {
+			check = "[ ]"
+		}
+
+		row := truncateWidth(fmt.Sprintf("%s %s%s", check, strings.Repeat("  ", strings.Count(path, "/")), path), width)
+
+		if start+index == t.cursor {
+			row = output.Yellow.Sprint(row)
+		}
+
+		t.writeRow(builder, row)
+	}
+}
+
+func (t *TUI) header() string {
+	state := "LIVE"
+	if t.paused {
+		state = "PAUSED"
+	}
+
+	if t.offset != 0 {
+		state += fmt.Sprintf(" -%d", t.offset)
+	}
+
+	pane := "merged"
+	if len(t.focus) != 0 {
+		pane = t.focus
+	}
+
+	grep := make([]string, len(t.grep))
+	for index, item := range t.grep {
+		grep[index] = item.String()
+	}
+
+	colorFilter := "all"
+	if t.colorFilter != nil {
+		colorFilter = severityOf(t.colorFilter)
+	}
+
+	return fmt.Sprintf("%s | pane: %s | grep: %s | level: %s | %d lines", state, pane, strings.Join(grep, ","), colorFilter, len(t.lines))
+}
+
+func (t *TUI) footer() string {
+	switch t.mode {
+	case tuiSearch:
+		return "/" + t.input
+	case tuiGrep:
+		return "grep: " + t.input
+	case tuiSources:
+		return "↑↓ move, space toggle, enter close"
+	default:
+		return "q quit, space pause, ↑↓ PgUp PgDn G scroll, / search, n N next, f grep, c level, s sources, tab pane"
+	}
+}
+
+func truncateWidth(text string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+
+	if utf8.RuneCountInString(text) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+
+	return string(runes[:width-1]) + "…"
+}
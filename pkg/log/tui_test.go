@@ -0,0 +1,119 @@
+package log
+
+import (
+	"testing"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+)
+
+func TestTUIVisibleLines(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		keys []string
+		want int
+	}{
+		"all": {
+			nil,
+			4,
+		},
+		"grep": {
+			[]string{"f", "t", "i", "m", "e", "o", "u", "t", "\r"},
+			2,
+		},
+		"level": {
+			[]string{"c"},
+			1,
+		},
+		"context disabled": {
+			[]string{"s", " ", "\r"},
+			2,
+		},
+		"pane": {
+			[]string{"\t"},
+			1,
+		},
+		"paused": {
+			[]string{" ", "collect"},
+			4,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			tui := NewTUI(nil, false, nil)
+
+			tui.collect(source{Context: "prod", Pod: "api-1", Container: "api"}, output.White, "request done", nil)
+			tui.collect(source{Context: "prod", Pod: "api-2", Container: "api"}, output.Red, "request timeout", nil)
+			tui.collect(source{Context: "staging", Pod: "api-1", Container: "api"}, output.White, "request done", nil)
+			tui.collect(source{Context: "staging", Pod: "api-1", Container: "api"}, output.Yellow, "upstream timeout", nil)
+
+			for _, key := range testCase.keys {
+				if key == "collect" {
+					tui.collect(source{Context: "prod", Pod: "api-1", Container: "api"}, output.White, "request done", nil)
+					continue
+				}
+
+				tui.handleKey(key)
+			}
+
+			if got := len(tui.visibleLines()); got != testCase.want {
+				t.Errorf("visibleLines() = %d, want %d", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestTUIStatus(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		writes     []string
+		want       string
+		bodyHeight int
+	}{
+		"none": {
+			nil,
+			"",
+			22,
+		},
+		"prefixed": {
+			[]string{output.Blue.Sprint("[prod] "), output.Red.Sprint("stream logs: connection refused") + "\n"},
+			"[prod] stream logs: connection refused",
+			21,
+		},
+		"pending": {
+			[]string{"first\n", "[prod] "},
+			"first",
+			21,
+		},
+		"several lines": {
+			[]string{"first\nsecond\n"},
+			"second",
+			21,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			tui := NewTUI(nil, false, nil)
+			status := tuiStatus{tui: tui}
+
+			for _, write := range testCase.writes {
+				_, _ = status.Write([]byte(write))
+			}
+
+			if got := tui.status(); got != testCase.want {
+				t.Errorf("status() = `%s`, want `%s`", got, testCase.want)
+			}
+
+			if got := tui.bodyHeightOf(24); got != testCase.bodyHeight {
+				t.Errorf("bodyHeightOf() = %d, want %d", got, testCase.bodyHeight)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package output
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const lokiPushPath = "/loki/api/v1/push"
+
+// Loki sends lines to a Loki push endpoint, one stream per labels' set
+type Loki struct {
+	client   *http.Client
+	endpoint string
+}
+
+type lokiStream struct {
+	Stream map[string]string `json:"stream"`
+	Values [][2]string       `json:"values"`
+}
+
+type lokiPush struct {
+	Streams []*lokiStream `json:"streams"`
+}
+
+// NewLoki creates a Loki sender, the push path being added if the URL has none
+func NewLoki(endpoint *url.URL) *Loki {
+	if len(strings.Trim(endpoint.Path, "/")) == 0 {
+		endpoint = endpoint.JoinPath(lokiPushPath)
+	}
+
+	return &Loki{
+		client:   &http.Client{},
+		endpoint: endpoint.String(),
+	}
+}
+
+func (l *Loki) Send(ctx context.Context, lines []Line) (int, error) {
+	var push lokiPush
+	streams := make(map[string]*lokiStream)
+
+	for _, line := range lines {
+		labels := maps.Clone(line.Labels)
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+
+		labels["level"] = line.Severity
+
+		key := labelsKey(labels)
+
+		stream, ok := streams[key]
+		if !ok {
+			stream = &lokiStream{Stream: labels}
+			streams[key] = stream
+			push.Streams = append(push.Streams, stream)
+		}
+
+		stream.Values = append(stream.Values, [2]string{strconv.FormatInt(line.Time.UnixNano(), 10), line.Message})
+	}
+
+	payload, err := json.Marshal(push)
+	if err != nil {
+		return 0, fmt.Errorf("marshal: %w", err)
+	}
+
+	if err := postJSON(ctx, l.client, l.endpoint, payload); err != nil {
+		return 0, err
+	}
+
+	return len(lines), nil
+}
+
+func (l *Loki) Close() error {
+	return nil
+}
+
+func labelsKey(labels map[string]string) string {
+	var builder strings.Builder
+
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		builder.WriteString(key)
+		builder.WriteString("=")
+		builder.WriteString(labels[key])
+		builder.WriteString(",")
+	}
+
+	return builder.String()
+}
+
+func postJSON(ctx context.Context, client *http.Client, endpoint string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post: %w", err)
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("HTTP/%d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	return nil
+}
@@ -0,0 +1,145 @@
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const otlpLogsPath = "/v1/logs"
+
+// otlpAttributes maps labels to OpenTelemetry's semantic conventions, others being prefixed by `kmux.`
+var otlpAttributes = map[string]string{
+	"context":   "k8s.cluster.name",
+	"namespace": "k8s.namespace.name",
+	"pod":       "k8s.pod.name",
+	"container": "k8s.container.name",
+	"node":      "k8s.node.name",
+	"file":      "log.file.path",
+}
+
+var otlpSeverities = map[string]int{
+	"debug": 5,
+	"info":  9,
+	"warn":  13,
+	"error": 17,
+}
+
+// OTLP sends lines to an OTLP/HTTP logs receiver, in JSON, one resource per labels' set
+type OTLP struct {
+	client   *http.Client
+	endpoint string
+}
+
+type otlpValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpRecord struct {
+	TimeUnixNano   string    `json:"timeUnixNano"`
+	SeverityText   string    `json:"severityText,omitempty"`
+	Body           otlpValue `json:"body"`
+	SeverityNumber int       `json:"severityNumber,omitempty"`
+}
+
+type otlpScope struct {
+	Name string `json:"name"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScope    `json:"scope"`
+	LogRecords []otlpRecord `json:"logRecords"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpExport struct {
+	ResourceLogs []*otlpResourceLogs `json:"resourceLogs"`
+}
+
+// NewOTLP creates an OTLP sender, the logs path being added if the URL has none
+func NewOTLP(endpoint *url.URL) *OTLP {
+	if len(strings.Trim(endpoint.Path, "/")) == 0 {
+		endpoint = endpoint.JoinPath(otlpLogsPath)
+	}
+
+	return &OTLP{
+		client:   &http.Client{},
+		endpoint: endpoint.String(),
+	}
+}
+
+func (o *OTLP) Send(ctx context.Context, lines []Line) (int, error) {
+	var export otlpExport
+	resources := make(map[string]*otlpResourceLogs)
+
+	for _, line := range lines {
+		key := labelsKey(line.Labels)
+
+		resource, ok := resources[key]
+		if !ok {
+			resource = &otlpResourceLogs{
+				Resource:  otlpResource{Attributes: otlpAttributesOf(line.Labels)},
+				ScopeLogs: []otlpScopeLogs{{Scope: otlpScope{Name: "kmux"}}},
+			}
+
+			resources[key] = resource
+			export.ResourceLogs = append(export.ResourceLogs, resource)
+		}
+
+		resource.ScopeLogs[0].LogRecords = append(resource.ScopeLogs[0].LogRecords, otlpRecord{
+			TimeUnixNano:   strconv.FormatInt(line.Time.UnixNano(), 10),
+			SeverityText:   strings.ToUpper(line.Severity),
+			SeverityNumber: otlpSeverities[line.Severity],
+			Body:           otlpValue{StringValue: line.Message},
+		})
+	}
+
+	payload, err := json.Marshal(export)
+	if err != nil {
+		return 0, fmt.Errorf("marshal: %w", err)
+	}
+
+	if err := postJSON(ctx, o.client, o.endpoint, payload); err != nil {
+		return 0, err
+	}
+
+	return len(lines), nil
+}
+
+func (o *OTLP) Close() error {
+	return nil
+}
+
+func otlpAttributesOf(labels map[string]string) []otlpAttribute {
+	attributes := make([]otlpAttribute, 0, len(labels))
+
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		name, ok := otlpAttributes[key]
+		if !ok {
+			name = "kmux." + key
+		}
+
+		attributes = append(attributes, otlpAttribute{Key: name, Value: otlpValue{StringValue: labels[key]}})
+	}
+
+	return attributes
+}
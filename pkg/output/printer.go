@@ -2,16 +2,24 @@ package output
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"strings"
 )
 
 type event struct {
+	writers *writers
+	flushed chan struct{}
 	prefix  string
 	message string
 	std     bool
 }
 
+type writers struct {
+	stdout io.Writer
+	stderr io.Writer
+}
+
 var (
 	done       = make(chan struct{})
 	outputChan = make(chan event, 8)
@@ -24,17 +32,29 @@ func init() {
 func startPrinter() {
 	defer close(done)
 
+	current := writers{stdout: os.Stdout, stderr: os.Stderr}
+
 	for outputEvent := range outputChan {
+		if outputEvent.writers != nil {
+			current = *outputEvent.writers
+			continue
+		}
+
+		if outputEvent.flushed != nil {
+			close(outputEvent.flushed)
+			continue
+		}
+
 		message := strings.TrimSuffix(outputEvent.message, "\n")
 
 		for _, line := range strings.Split(message, "\n") {
 			if len(outputEvent.prefix) > 0 {
-				_, _ = fmt.Fprint(os.Stderr, outputEvent.prefix)
+				_, _ = fmt.Fprint(current.stderr, outputEvent.prefix)
 			}
 
-			fd := os.Stderr
+			fd := current.stderr
 			if outputEvent.std {
-				fd = os.Stdout
+				fd = current.stdout
 			}
 
 			_, _ = fmt.Fprint(fd, line, "\n")
@@ -42,7 +62,9 @@ func startPrinter() {
 	}
 }
 
+// Close flushes the sinks, then stops the printer
 func Close() {
+	closeSinks()
 	close(outputChan)
 }
 
@@ -50,6 +72,18 @@ func Done() <-chan struct{} {
 	return done
 }
 
+// Redirect writes the next outputs to the given writers, e.g. for capturing them in tests
+func Redirect(stdout, stderr io.Writer) {
+	outputChan <- event{writers: &writers{stdout: stdout, stderr: stderr}}
+}
+
+// Flush waits for every pending output to be written
+func Flush() {
+	flushed := make(chan struct{})
+	outputChan <- event{flushed: flushed}
+	<-flushed
+}
+
 func outputContent(std bool, prefix, message string) {
 	outputChan <- event{std: std, prefix: prefix, message: message}
 }
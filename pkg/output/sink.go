@@ -0,0 +1,228 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	sinkBuffer   = 1024
+	sinkAttempts = 3
+	sinkBackoff  = 500 * time.Millisecond
+	sinkTimeout  = 10 * time.Second
+)
+
+// Line is a log line shipped to sinks, labels being its source (context, namespace, pod, container, ...)
+type Line struct {
+	Time     time.Time
+	Labels   map[string]string
+	Severity string
+	Message  string
+}
+
+// Sender sends a batch of lines with a given protocol, returning the number of lines delivered before an error
+type Sender interface {
+	Send(context.Context, []Line) (int, error)
+	Close() error
+}
+
+// Sink ships lines to a sender, in batches, retrying failed ones
+type Sink struct {
+	sender   Sender
+	lines    chan Line
+	done     chan struct{}
+	name     string
+	batch    int
+	interval time.Duration
+	dropped  atomic.Uint64
+}
+
+var (
+	sinks      []*Sink
+	sinksMutex sync.RWMutex
+)
+
+// ParseSink creates a sink from a `TYPE=URL` value, TYPE being one of `loki`, `otlp` or `syslog`
+func ParseSink(value string, batch uint, interval time.Duration) (*Sink, error) {
+	kind, rawURL, found := strings.Cut(value, "=")
+	if !found {
+		return nil, fmt.Errorf("sink `%s` is not in the `TYPE=URL` form", value)
+	}
+
+	endpoint, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("parse url: %w", err)
+	}
+
+	var sender Sender
+
+	switch kind {
+	case "loki":
+		sender = NewLoki(endpoint)
+	case "otlp":
+		sender = NewOTLP(endpoint)
+	case "syslog":
+		sender, err = NewSyslog(endpoint)
+	default:
+		return nil, fmt.Errorf("unhandled sink type `%s`", kind)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", kind, err)
+	}
+
+	return NewSink(kind, sender, batch, interval), nil
+}
+
+// NewSink starts shipping lines to the sender once `batch` lines are buffered or every `interval`
+func NewSink(name string, sender Sender, batch uint, interval time.Duration) *Sink {
+	sink := &Sink{
+		name:     name,
+		sender:   sender,
+		batch:    max(int(batch), 1),
+		interval: interval,
+		lines:    make(chan Line, sinkBuffer),
+		done:     make(chan struct{}),
+	}
+
+	go sink.start()
+
+	return sink
+}
+
+func (s *Sink) start() {
+	defer close(s.done)
+
+	var ticks <-chan time.Time
+
+	if s.interval > 0 {
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		ticks = ticker.C
+	}
+
+	batch := make([]Line, 0, s.batch)
+
+	flush := func() {
+		if len(batch) != 0 {
+			s.send(batch)
+			batch = make([]Line, 0, s.batch)
+		}
+	}
+
+	for {
+		select {
+		case line, ok := <-s.lines:
+			if !ok {
+				flush()
+				return
+			}
+
+			batch = append(batch, line)
+
+			if len(batch) >= s.batch {
+				flush()
+			}
+
+		case <-ticks:
+			flush()
+		}
+	}
+}
+
+// send retries only the lines not delivered by the previous attempt
+func (s *Sink) send(batch []Line) {
+	backoff := sinkBackoff
+
+	var err error
+
+	for attempt := 1; attempt <= sinkAttempts; attempt++ {
+		var sent int
+
+		ctx, cancel := context.WithTimeout(context.Background(), sinkTimeout)
+		sent, err = s.sender.Send(ctx, batch)
+		cancel()
+
+		if err == nil {
+			return
+		}
+
+		batch = batch[min(max(sent, 0), len(batch)):]
+		if len(batch) == 0 {
+			return
+		}
+
+		if attempt != sinkAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	Err("", "sink `%s`: %d lines dropped after %d attempts: %s", s.name, len(batch), sinkAttempts, err)
+}
+
+// Ship queues the line for the next batch, dropping it if the sink is too slow, for never blocking the printing
+func (s *Sink) Ship(line Line) {
+	select {
+	case s.lines <- line:
+	default:
+		s.dropped.Add(1)
+	}
+}
+
+// Close sends the pending lines, reports dropped ones and closes the sender
+func (s *Sink) Close() {
+	close(s.lines)
+	<-s.done
+
+	if dropped := s.dropped.Load(); dropped != 0 {
+		Err("", "sink `%s`: %d lines dropped, sink being too slow or unavailable", s.name, dropped)
+	}
+
+	if err := s.sender.Close(); err != nil {
+		Err("", "sink `%s`: close: %s", s.name, err)
+	}
+}
+
+// AddSink registers the sink for receiving every shipped line, until output is closed
+func AddSink(sink *Sink) {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+
+	sinks = append(sinks, sink)
+}
+
+// Shipping returns true if at least one sink is registered
+func Shipping() bool {
+	sinksMutex.RLock()
+	defer sinksMutex.RUnlock()
+
+	return len(sinks) != 0
+}
+
+// Ship sends the line to every registered sink
+func Ship(line Line) {
+	sinksMutex.RLock()
+	defer sinksMutex.RUnlock()
+
+	for _, sink := range sinks {
+		sink.Ship(line)
+	}
+}
+
+func closeSinks() {
+	sinksMutex.Lock()
+	defer sinksMutex.Unlock()
+
+	for _, sink := range sinks {
+		sink.Close()
+	}
+
+	sinks = nil
+}
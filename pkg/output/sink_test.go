@@ -0,0 +1,279 @@
+package output
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var sinkTime = time.Date(2024, 5, 1, 12, 0, 0, 123456789, time.UTC)
+
+func sinkLines() []Line {
+	return []Line{
+		{Time: sinkTime, Labels: map[string]string{"context": "prod", "pod": "api-1", "container": "api"}, Severity: "error", Message: "boom"},
+		{Time: sinkTime, Labels: map[string]string{"context": "prod", "pod": "api-1", "container": "api"}, Severity: "error", Message: "again"},
+		{Time: sinkTime, Labels: map[string]string{"context": "prod", "pod": "api-2", "container": "api"}, Severity: "info", Message: "ready"},
+	}
+}
+
+func TestHTTPSenders(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		sender   func(*url.URL) Sender
+		wantPath string
+		want     string
+	}{
+		"loki": {
+			func(endpoint *url.URL) Sender { return NewLoki(endpoint) },
+			"/loki/api/v1/push",
+			`{"streams":[{"stream":{"container":"api","context":"prod","level":"error","pod":"api-1"},"values":[["1714564800123456789","boom"],["1714564800123456789","again"]]},{"stream":{"container":"api","context":"prod","level":"info","pod":"api-2"},"values":[["1714564800123456789","ready"]]}]}`,
+		},
+		"otlp": {
+			func(endpoint *url.URL) Sender { return NewOTLP(endpoint) },
+			"/v1/logs",
+			`{"resourceLogs":[{"resource":{"attributes":[{"key":"k8s.container.name","value":{"stringValue":"api"}},{"key":"k8s.cluster.name","value":{"stringValue":"prod"}},{"key":"k8s.pod.name","value":{"stringValue":"api-1"}}]},"scopeLogs":[{"scope":{"name":"kmux"},"logRecords":[{"timeUnixNano":"1714564800123456789","severityText":"ERROR","body":{"stringValue":"boom"},"severityNumber":17},{"timeUnixNano":"1714564800123456789","severityText":"ERROR","body":{"stringValue":"again"},"severityNumber":17}]}]},{"resource":{"attributes":[{"key":"k8s.container.name","value":{"stringValue":"api"}},{"key":"k8s.cluster.name","value":{"stringValue":"prod"}},{"key":"k8s.pod.name","value":{"stringValue":"api-2"}}]},"scopeLogs":[{"scope":{"name":"kmux"},"logRecords":[{"timeUnixNano":"1714564800123456789","severityText":"INFO","body":{"stringValue":"ready"},"severityNumber":9}]}]}]}`,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var gotPath, got string
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				payload, _ := io.ReadAll(r.Body)
+				gotPath, got = r.URL.Path, string(payload)
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer server.Close()
+
+			endpoint, _ := url.Parse(server.URL)
+
+			if _, err := testCase.sender(endpoint).Send(context.Background(), sinkLines()); err != nil {
+				t.Fatalf("Send() = %s", err)
+			}
+
+			if gotPath != testCase.wantPath {
+				t.Errorf("Send() path = `%s`, want `%s`", gotPath, testCase.wantPath)
+			}
+
+			if !json.Valid([]byte(got)) || got != testCase.want {
+				t.Errorf("Send() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestSyslog(t *testing.T) {
+	t.Parallel()
+
+	want := []string{
+		`<11>1 2024-05-01T12:00:00.123456Z api-1 api - - [kmux@32473 container="api" context="prod" pod="api-1"] boom`,
+		`<11>1 2024-05-01T12:00:00.123456Z api-1 api - - [kmux@32473 container="api" context="prod" pod="api-1"] again`,
+		`<14>1 2024-05-01T12:00:00.123456Z api-2 api - - [kmux@32473 container="api" context="prod" pod="api-2"] ready`,
+	}
+
+	t.Run("udp", func(t *testing.T) {
+		t.Parallel()
+
+		conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %s", err)
+		}
+		defer conn.Close()
+
+		sender, err := NewSyslog(&url.URL{Scheme: "udp", Host: conn.LocalAddr().String()})
+		if err != nil {
+			t.Fatalf("NewSyslog() = %s", err)
+		}
+		defer sender.Close()
+
+		if _, err := sender.Send(context.Background(), sinkLines()); err != nil {
+			t.Fatalf("Send() = %s", err)
+		}
+
+		buffer := make([]byte, 1024)
+
+		for _, wantLine := range want {
+			_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+
+			size, _, err := conn.ReadFrom(buffer)
+			if err != nil {
+				t.Fatalf("read: %s", err)
+			}
+
+			if got := string(buffer[:size]); got != wantLine {
+				t.Errorf("Send() = `%s`, want `%s`", got, wantLine)
+			}
+		}
+	})
+
+	t.Run("tcp", func(t *testing.T) {
+		t.Parallel()
+
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("listen: %s", err)
+		}
+		defer listener.Close()
+
+		sender, err := NewSyslog(&url.URL{Scheme: "tcp", Host: listener.Addr().String()})
+		if err != nil {
+			t.Fatalf("NewSyslog() = %s", err)
+		}
+
+		if _, err := sender.Send(context.Background(), sinkLines()); err != nil {
+			t.Fatalf("Send() = %s", err)
+		}
+
+		if err := sender.Close(); err != nil {
+			t.Fatalf("Close() = %s", err)
+		}
+
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Fatalf("accept: %s", err)
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+
+		for _, wantLine := range want {
+			var size int
+			if _, err := fmt.Fscanf(reader, "%d ", &size); err != nil {
+				t.Fatalf("read length: %s", err)
+			}
+
+			payload := make([]byte, size)
+			if _, err := io.ReadFull(reader, payload); err != nil {
+				t.Fatalf("read message: %s", err)
+			}
+
+			if got := string(payload); got != wantLine {
+				t.Errorf("Send() = `%s`, want `%s`", got, wantLine)
+			}
+		}
+	})
+}
+
+type failingSender struct {
+	sent     chan []Line
+	failures atomic.Int32
+}
+
+// Send delivers only the first line when failing, as a partial write would
+func (f *failingSender) Send(_ context.Context, lines []Line) (int, error) {
+	if f.failures.Add(-1) >= 0 {
+		f.sent <- lines[:1]
+		return 1, io.ErrUnexpectedEOF
+	}
+
+	f.sent <- lines
+
+	return len(lines), nil
+}
+
+func (f *failingSender) Close() error {
+	close(f.sent)
+	return nil
+}
+
+func TestSink(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		batch    uint
+		failures int32
+		want     []int
+	}{
+		"batches": {
+			2,
+			0,
+			[]int{2, 1},
+		},
+		"retry undelivered only": {
+			5,
+			1,
+			[]int{1, 2},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			sender := &failingSender{sent: make(chan []Line, 4)}
+			sender.failures.Store(testCase.failures)
+
+			sink := NewSink(intention, sender, testCase.batch, 0)
+
+			for _, line := range sinkLines() {
+				sink.Ship(line)
+			}
+
+			sink.Close()
+
+			var got []int
+			for lines := range sender.sent {
+				got = append(got, len(lines))
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("Ship() batches = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
+
+type blockingSender struct {
+	sending chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingSender) Send(_ context.Context, lines []Line) (int, error) {
+	select {
+	case b.sending <- struct{}{}:
+	default:
+	}
+
+	<-b.release
+
+	return len(lines), nil
+}
+
+func (b *blockingSender) Close() error {
+	return nil
+}
+
+func TestSinkDropped(t *testing.T) {
+	t.Parallel()
+
+	sender := &blockingSender{sending: make(chan struct{}, 1), release: make(chan struct{})}
+	sink := NewSink("blocking", sender, 1, 0)
+
+	sink.Ship(Line{Message: "first"})
+	<-sender.sending
+
+	for range sinkBuffer + 5 {
+		sink.Ship(Line{Message: "next"})
+	}
+
+	if got := sink.dropped.Load(); got != 5 {
+		t.Errorf("Ship() dropped = %d, want %d", got, 5)
+	}
+
+	close(sender.release)
+	sink.Close()
+}
@@ -0,0 +1,144 @@
+package output
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	syslogFacility   = 1 // user-level messages
+	syslogEnterprise = "kmux@32473"
+	syslogTimeLayout = "2006-01-02T15:04:05.000000Z07:00"
+	syslogAppLength  = 48
+)
+
+var syslogSeverities = map[string]int{
+	"error": 3,
+	"warn":  4,
+	"info":  6,
+	"debug": 7,
+}
+
+var syslogEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, `]`, `\]`)
+
+// Syslog sends lines in RFC 5424 format, over UDP or over TCP with octet-counting framing
+type Syslog struct {
+	conn    net.Conn
+	network string
+	address string
+	mutex   sync.Mutex
+}
+
+// NewSyslog creates a syslog sender for an `udp://host:port` or `tcp://host:port` URL
+func NewSyslog(endpoint *url.URL) (*Syslog, error) {
+	switch endpoint.Scheme {
+	case "udp", "tcp":
+	default:
+		return nil, fmt.Errorf("unhandled scheme `%s`, one of (udp, tcp)", endpoint.Scheme)
+	}
+
+	return &Syslog{
+		network: endpoint.Scheme,
+		address: endpoint.Host,
+	}, nil
+}
+
+// Send writes lines one by one, returning the number of lines written for not resending them on retry
+func (s *Syslog) Send(ctx context.Context, lines []Line) (int, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn == nil {
+		var dialer net.Dialer
+
+		conn, err := dialer.DialContext(ctx, s.network, s.address)
+		if err != nil {
+			return 0, fmt.Errorf("dial: %w", err)
+		}
+
+		s.conn = conn
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := s.conn.SetWriteDeadline(deadline); err != nil {
+			return 0, fmt.Errorf("set deadline: %w", err)
+		}
+	}
+
+	for index, line := range lines {
+		message := formatSyslog(line)
+
+		if s.network == "tcp" {
+			message = fmt.Sprintf("%d %s", len(message), message)
+		}
+
+		if _, err := s.conn.Write([]byte(message)); err != nil {
+			// connection is reopened on next attempt
+			_ = s.conn.Close()
+			s.conn = nil
+
+			return index, fmt.Errorf("write: %w", err)
+		}
+	}
+
+	return len(lines), nil
+}
+
+func (s *Syslog) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.conn == nil {
+		return nil
+	}
+
+	return s.conn.Close()
+}
+
+func formatSyslog(line Line) string {
+	severity, ok := syslogSeverities[line.Severity]
+	if !ok {
+		severity = syslogSeverities["info"]
+	}
+
+	hostname := syslogValue(line.Labels["pod"])
+
+	appName := syslogValue(line.Labels["container"])
+	if len(appName) > syslogAppLength {
+		appName = appName[:syslogAppLength]
+	}
+
+	return fmt.Sprintf("<%d>1 %s %s %s - - %s %s", syslogFacility*8+severity, line.Time.Format(syslogTimeLayout), hostname, appName, syslogData(line.Labels), line.Message)
+}
+
+func syslogValue(value string) string {
+	if len(value) == 0 {
+		return "-"
+	}
+
+	return strings.ReplaceAll(value, " ", "_")
+}
+
+func syslogData(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "-"
+	}
+
+	var builder strings.Builder
+
+	builder.WriteString("[" + syslogEnterprise)
+
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		builder.WriteString(fmt.Sprintf(` %s="%s"`, key, syslogEscaper.Replace(labels[key])))
+	}
+
+	builder.WriteString("]")
+
+	return builder.String()
+}
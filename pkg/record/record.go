@@ -0,0 +1,178 @@
+package record
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/output"
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	HeaderType = "header"
+	LogType    = "log"
+	PodType    = "pod"
+	EventType  = "event"
+)
+
+// Entry is a line of a session, a log line with its source, a pod's state or a Kubernetes event
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Object    *v1.Pod   `json:"object,omitempty"`
+	Event     *v1.Event `json:"event,omitempty"`
+	Type      string    `json:"type"`
+	Command   string    `json:"command,omitempty"`
+	Context   string    `json:"context,omitempty"`
+	Namespace string    `json:"namespace,omitempty"`
+	Pod       string    `json:"pod,omitempty"`
+	Container string    `json:"container,omitempty"`
+	Node      string    `json:"node,omitempty"`
+	File      string    `json:"file,omitempty"`
+	Resource  string    `json:"resource,omitempty"`
+	Revision  string    `json:"revision,omitempty"`
+	Timestamp string    `json:"timestamp,omitempty"`
+	Text      string    `json:"text,omitempty"`
+	Hints     *Hints    `json:"hints,omitempty"`
+	Contexts  []string  `json:"contexts,omitempty"`
+}
+
+// Hints are the parsing hints of a log source, resolved from flags and pod's annotations when recorded
+type Hints struct {
+	LogFormat  string   `json:"logFormat,omitempty"`
+	LevelKeys  []string `json:"levelKeys,omitempty"`
+	StatusKeys []string `json:"statusKeys,omitempty"`
+}
+
+// Recorder writes entries of a session as gzipped JSON lines
+type Recorder struct {
+	file    *os.File
+	writer  *gzip.Writer
+	encoder *json.Encoder
+	mutex   sync.Mutex
+}
+
+// New creates the session's file, readable only by its owner, recorded pods and lines being possibly sensitive
+func New(path, command string, contexts []string) (*Recorder, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("create: %w", err)
+	}
+
+	writer := gzip.NewWriter(file)
+
+	recorder := &Recorder{
+		file:    file,
+		writer:  writer,
+		encoder: json.NewEncoder(writer),
+	}
+
+	if err := recorder.encoder.Encode(Entry{Time: time.Now(), Type: HeaderType, Command: command, Contexts: contexts}); err != nil {
+		return nil, errors.Join(fmt.Errorf("write header: %w", err), file.Close())
+	}
+
+	return recorder, nil
+}
+
+func (r *Recorder) Record(entry Entry) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if entry.Time.IsZero() {
+		entry.Time = time.Now()
+	}
+
+	if err := r.encoder.Encode(entry); err != nil {
+		output.Err("", "record entry: %s", err)
+	}
+}
+
+func (r *Recorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return errors.Join(r.writer.Close(), r.file.Close())
+}
+
+// Reader reads a recorded session
+type Reader struct {
+	file    *os.File
+	reader  *gzip.Reader
+	decoder *json.Decoder
+	Header  Entry
+}
+
+func Open(path string) (*Reader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open: %w", err)
+	}
+
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("gzip: %w", err), file.Close())
+	}
+
+	output := &Reader{
+		file:    file,
+		reader:  reader,
+		decoder: json.NewDecoder(reader),
+	}
+
+	if err := output.decoder.Decode(&output.Header); err != nil {
+		return nil, errors.Join(fmt.Errorf("read header: %w", err), output.Close())
+	}
+
+	if output.Header.Type != HeaderType {
+		return nil, errors.Join(errors.New("not a kmux session"), output.Close())
+	}
+
+	return output, nil
+}
+
+// Replay calls the handler for every entry, waiting between them as they were recorded, accelerated by speed.
+// A speed of zero replays without waiting.
+func (r *Reader) Replay(ctx context.Context, speed float64, handler func(Entry)) error {
+	previous := r.Header.Time
+
+	for {
+		var entry Entry
+
+		if err := r.decoder.Decode(&entry); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return fmt.Errorf("decode entry: %w", err)
+		}
+
+		if speed > 0 {
+			if delay := time.Duration(float64(entry.Time.Sub(previous)) / speed); delay > 0 {
+				timer := time.NewTimer(delay)
+
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return nil
+				case <-timer.C:
+				}
+			}
+		} else if ctx.Err() != nil {
+			return nil
+		}
+
+		previous = entry.Time
+
+		handler(entry)
+	}
+}
+
+func (r *Reader) Close() error {
+	return errors.Join(r.reader.Close(), r.file.Close())
+}
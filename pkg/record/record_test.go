@@ -0,0 +1,96 @@
+package record
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestReplay(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now()
+
+	cases := map[string]struct {
+		entries []Entry
+		speed   float64
+		want    []string
+	}{
+		"empty": {
+			nil,
+			0,
+			nil,
+		},
+		"in order": {
+			[]Entry{
+				{Time: start, Type: LogType, Pod: "api-1", Text: "starting"},
+				{Time: start.Add(time.Millisecond), Type: LogType, Pod: "api-2", Text: "ready"},
+			},
+			0,
+			[]string{"api-1 starting", "api-2 ready"},
+		},
+		"paced": {
+			[]Entry{
+				{Time: start.Add(10 * time.Millisecond), Type: LogType, Pod: "api-1", Text: "ready"},
+			},
+			10,
+			[]string{"api-1 ready"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "session.gz")
+
+			recorder, err := New(path, "log", []string{"prod"})
+			if err != nil {
+				t.Fatalf("New() = %s", err)
+			}
+
+			for _, entry := range testCase.entries {
+				recorder.Record(entry)
+			}
+
+			if err := recorder.Close(); err != nil {
+				t.Fatalf("Close() = %s", err)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatalf("Stat() = %s", err)
+			}
+
+			if got := info.Mode().Perm(); got != 0o600 {
+				t.Errorf("New() = %v, want mode %v", got, os.FileMode(0o600))
+			}
+
+			reader, err := Open(path)
+			if err != nil {
+				t.Fatalf("Open() = %s", err)
+			}
+
+			defer reader.Close()
+
+			if reader.Header.Command != "log" || !reflect.DeepEqual(reader.Header.Contexts, []string{"prod"}) {
+				t.Errorf("Header = %+v, want `log` command on `prod`", reader.Header)
+			}
+
+			var got []string
+
+			if err := reader.Replay(context.Background(), testCase.speed, func(entry Entry) {
+				got = append(got, entry.Pod+" "+entry.Text)
+			}); err != nil {
+				t.Fatalf("Replay() = %s", err)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("Replay() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
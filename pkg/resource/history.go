@@ -0,0 +1,189 @@
+package resource
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// RevisionAnnotation holds the revision of a Deployment's ReplicaSet
+const RevisionAnnotation = "deployment.kubernetes.io/revision"
+
+const revisionHashLabel = "controller-revision-hash"
+
+// Revision is a past or current revision of a workload, with its pod's spec
+type Revision struct {
+	Created  time.Time
+	Name     string
+	Spec     v1.PodSpec
+	Number   int64
+	Replicas int32
+}
+
+// History lists revisions of the workload, newest first: ReplicaSets for Deployments, ControllerRevisions for StatefulSets and DaemonSets
+func History(ctx context.Context, kube client.Kube, kind, name string) ([]Revision, error) {
+	var revisions []Revision
+	var err error
+
+	switch kind {
+	case "deploy", "deployment", "deployments":
+		revisions, err = deploymentHistory(ctx, kube, name)
+
+	case "ds", "daemonset", "daemonsets", "sts", "statefulset", "statefulsets":
+		revisions, err = controllerHistory(ctx, kube, kind, name)
+
+	default:
+		return nil, fmt.Errorf("history is not available for resource type `%s`", kind)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(revisions, func(a, b Revision) int {
+		return cmp.Compare(b.Number, a.Number)
+	})
+
+	return revisions, nil
+}
+
+func deploymentHistory(ctx context.Context, kube client.Kube, name string) ([]Revision, error) {
+	deployment, err := kube.AppsV1().Deployments(kube.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get deployment: %w", err)
+	}
+
+	replicaSets, err := kube.AppsV1().ReplicaSets(kube.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: metav1.FormatLabelSelector(deployment.Spec.Selector),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list replicasets: %w", err)
+	}
+
+	var output []Revision
+
+	for _, replicaSet := range replicaSets.Items {
+		if !isOwnedBy(replicaSet.OwnerReferences, deployment.UID) {
+			continue
+		}
+
+		number, _ := strconv.ParseInt(replicaSet.Annotations[RevisionAnnotation], 10, 64)
+
+		output = append(output, Revision{
+			Number:   number,
+			Name:     replicaSet.Name,
+			Created:  replicaSet.CreationTimestamp.Time,
+			Replicas: replicaSet.Status.Replicas,
+			Spec:     replicaSet.Spec.Template.Spec,
+		})
+	}
+
+	return output, nil
+}
+
+func controllerHistory(ctx context.Context, kube client.Kube, kind, name string) ([]Revision, error) {
+	var uid types.UID
+	var selector *metav1.LabelSelector
+
+	switch kind {
+	case "ds", "daemonset", "daemonsets":
+		daemonSet, err := kube.AppsV1().DaemonSets(kube.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get daemonset: %w", err)
+		}
+
+		uid, selector = daemonSet.UID, daemonSet.Spec.Selector
+
+	default:
+		statefulSet, err := kube.AppsV1().StatefulSets(kube.Namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("get statefulset: %w", err)
+		}
+
+		uid, selector = statefulSet.UID, statefulSet.Spec.Selector
+	}
+
+	options := metav1.ListOptions{LabelSelector: metav1.FormatLabelSelector(selector)}
+
+	controllerRevisions, err := kube.AppsV1().ControllerRevisions(kube.Namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("list controllerrevisions: %w", err)
+	}
+
+	pods, err := kube.CoreV1().Pods(kube.Namespace).List(ctx, options)
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	var output []Revision
+
+	for _, controllerRevision := range controllerRevisions.Items {
+		if !isOwnedBy(controllerRevision.OwnerReferences, uid) {
+			continue
+		}
+
+		spec, err := controllerRevisionSpec(controllerRevision)
+		if err != nil {
+			return nil, fmt.Errorf("controllerrevision `%s`: %w", controllerRevision.Name, err)
+		}
+
+		output = append(output, Revision{
+			Number:   controllerRevision.Revision,
+			Name:     controllerRevision.Name,
+			Created:  controllerRevision.CreationTimestamp.Time,
+			Replicas: revisionReplicas(controllerRevision, pods.Items),
+			Spec:     spec,
+		})
+	}
+
+	return output, nil
+}
+
+// controllerRevisionSpec decodes the pod's template stored as a patch of the workload
+func controllerRevisionSpec(controllerRevision appsv1.ControllerRevision) (v1.PodSpec, error) {
+	var patch struct {
+		Spec struct {
+			Template v1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+
+	if err := json.Unmarshal(controllerRevision.Data.Raw, &patch); err != nil {
+		return v1.PodSpec{}, fmt.Errorf("unmarshal: %w", err)
+	}
+
+	return patch.Spec.Template.Spec, nil
+}
+
+// revisionReplicas counts pods of the revision. StatefulSet's pods are labeled with the name of the revision,
+// DaemonSet's ones with its hash.
+func revisionReplicas(controllerRevision appsv1.ControllerRevision, pods []v1.Pod) int32 {
+	var count int32
+
+	for _, pod := range pods {
+		if hash := pod.Labels[revisionHashLabel]; hash == controllerRevision.Name || (len(hash) != 0 && hash == controllerRevision.Labels[revisionHashLabel]) {
+			count++
+		}
+	}
+
+	return count
+}
+
+func isOwnedBy(owners []metav1.OwnerReference, uid types.UID) bool {
+	for _, owner := range owners {
+		if owner.UID == uid {
+			return true
+		}
+	}
+
+	return false
+}
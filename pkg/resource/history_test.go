@@ -0,0 +1,67 @@
+package resource
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestControllerRevisionSpec(t *testing.T) {
+	t.Parallel()
+
+	controllerRevision := appsv1.ControllerRevision{
+		Data: runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"spec":{"containers":[{"name":"api","image":"vibioh/api:1.2.3"}]}}}}`)},
+	}
+
+	got, err := controllerRevisionSpec(controllerRevision)
+	if err != nil {
+		t.Fatalf("controllerRevisionSpec() = %s", err)
+	}
+
+	if len(got.Containers) != 1 || got.Containers[0].Image != "vibioh/api:1.2.3" {
+		t.Errorf("controllerRevisionSpec() = %+v, want `vibioh/api:1.2.3` image", got.Containers)
+	}
+}
+
+func TestRevisionReplicas(t *testing.T) {
+	t.Parallel()
+
+	podWithHash := func(hash string) v1.Pod {
+		return v1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{revisionHashLabel: hash}}}
+	}
+
+	cases := map[string]struct {
+		controllerRevision appsv1.ControllerRevision
+		pods               []v1.Pod
+		want               int32
+	}{
+		"statefulset": {
+			appsv1.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Name: "web-6c5b7f9d8"}},
+			[]v1.Pod{podWithHash("web-6c5b7f9d8"), podWithHash("web-6c5b7f9d8"), podWithHash("web-54f8b7c6d")},
+			2,
+		},
+		"daemonset": {
+			appsv1.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Name: "agent-6c5b7f9d8", Labels: map[string]string{revisionHashLabel: "6c5b7f9d8"}}},
+			[]v1.Pod{podWithHash("6c5b7f9d8"), podWithHash("54f8b7c6d")},
+			1,
+		},
+		"no label": {
+			appsv1.ControllerRevision{ObjectMeta: metav1.ObjectMeta{Name: "agent-6c5b7f9d8"}},
+			[]v1.Pod{{}},
+			0,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := revisionReplicas(testCase.controllerRevision, testCase.pods); got != testCase.want {
+				t.Errorf("revisionReplicas() = %d, want %d", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package resource
+
+import (
+	"slices"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultRegistry  = "docker.io"
+	defaultNamespace = "library/"
+	defaultTag       = "latest"
+)
+
+// Image is a container image reference, normalized like container runtimes do
+type Image struct {
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
+}
+
+// ParseImage parses an image reference, e.g. `nginx:1.27` being `docker.io/library/nginx:1.27`
+func ParseImage(reference string) Image {
+	var image Image
+
+	reference, image.Digest, _ = strings.Cut(reference, "@")
+
+	if index := strings.LastIndex(reference, ":"); index != -1 && !strings.Contains(reference[index:], "/") {
+		reference, image.Tag = reference[:index], reference[index+1:]
+	}
+
+	image.Registry, image.Repository = defaultRegistry, reference
+
+	if domain, path, found := strings.Cut(reference, "/"); found && (strings.ContainsAny(domain, ".:") || domain == "localhost") {
+		image.Registry, image.Repository = domain, path
+	}
+
+	if image.Registry == defaultRegistry && !strings.Contains(image.Repository, "/") {
+		image.Repository = defaultNamespace + image.Repository
+	}
+
+	if len(image.Tag) == 0 && len(image.Digest) == 0 {
+		image.Tag = defaultTag
+	}
+
+	return image
+}
+
+func (i Image) String() string {
+	reference := i.Registry + "/" + i.Repository
+
+	if len(i.Tag) != 0 {
+		reference += ":" + i.Tag
+	}
+
+	if len(i.Digest) != 0 {
+		reference += "@" + i.Digest
+	}
+
+	return reference
+}
+
+// Digest returns the digest of an image ID reported by the container runtime, e.g. `docker.io/library/nginx@sha256:...`
+func Digest(imageID string) string {
+	if index := strings.LastIndex(imageID, "@"); index != -1 {
+		return imageID[index+1:]
+	}
+
+	return strings.TrimPrefix(imageID, "docker://")
+}
+
+// ContainerStatus returns the status of the container, from init, main or ephemeral statuses
+func ContainerStatus(pod v1.Pod, container string) (v1.ContainerStatus, bool) {
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses, pod.Status.EphemeralContainerStatuses) {
+		if status.Name == container {
+			return status, true
+		}
+	}
+
+	return v1.ContainerStatus{}, false
+}
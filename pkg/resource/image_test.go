@@ -0,0 +1,81 @@
+package resource
+
+import (
+	"testing"
+)
+
+func TestParseImage(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		reference string
+		want      Image
+	}{
+		"short": {
+			"nginx",
+			Image{Registry: "docker.io", Repository: "library/nginx", Tag: "latest"},
+		},
+		"tag": {
+			"vibioh/kmux:1.2.3",
+			Image{Registry: "docker.io", Repository: "vibioh/kmux", Tag: "1.2.3"},
+		},
+		"registry with port": {
+			"localhost:5000/api",
+			Image{Registry: "localhost:5000", Repository: "api", Tag: "latest"},
+		},
+		"digest": {
+			"ghcr.io/vibioh/kmux:1.2.3@sha256:abcd",
+			Image{Registry: "ghcr.io", Repository: "vibioh/kmux", Tag: "1.2.3", Digest: "sha256:abcd"},
+		},
+		"digest only": {
+			"gcr.io/distroless/static@sha256:abcd",
+			Image{Registry: "gcr.io", Repository: "distroless/static", Digest: "sha256:abcd"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ParseImage(testCase.reference); got != testCase.want {
+				t.Errorf("ParseImage() = %+v, want %+v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestDigest(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		imageID string
+		want    string
+	}{
+		"containerd": {
+			"docker.io/library/nginx@sha256:abcd",
+			"sha256:abcd",
+		},
+		"docker": {
+			"docker-pullable://nginx@sha256:abcd",
+			"sha256:abcd",
+		},
+		"image id": {
+			"sha256:abcd",
+			"sha256:abcd",
+		},
+		"pending": {
+			"",
+			"",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Digest(testCase.imageID); got != testCase.want {
+				t.Errorf("Digest() = `%s`, want `%s`", got, testCase.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package resource
 
 import (
+	"fmt"
 	"regexp"
+	"slices"
+	"strings"
 
 	v1 "k8s.io/api/core/v1"
 )
@@ -15,7 +18,136 @@ func IsService(name string) bool {
 	}
 }
 
-func IsContainedSelected(container v1.Container, filter *regexp.Regexp) bool {
+func IsNamespace(name string) bool {
+	switch name {
+	case "ns", "namespace", "namespaces":
+		return true
+	default:
+		return false
+	}
+}
+
+// IsTargetKind returns true if pods can be found from the resource type
+func IsTargetKind(kind string) bool {
+	switch kind {
+	case "cj", "cronjob", "cronjobs",
+		"ds", "daemonset", "daemonsets",
+		"deploy", "deployment", "deployments",
+		"job", "jobs",
+		"no", "node", "nodes",
+		"ns", "namespace", "namespaces",
+		"po", "pod", "pods",
+		"sts", "statefulset", "statefulsets",
+		"svc", "service", "services":
+		return true
+	default:
+		return false
+	}
+}
+
+// Target is a resource to find pods from
+type Target struct {
+	Kind string
+	Name string
+}
+
+func (t Target) String() string {
+	return t.Kind + "/" + t.Name
+}
+
+// ParseTargets parses either a `TYPE NAME` pair, or many `TYPE/NAME`
+func ParseTargets(args []string) ([]Target, error) {
+	if len(args) == 2 && !strings.Contains(args[0], "/") && !strings.Contains(args[1], "/") {
+		if !IsTargetKind(args[0]) {
+			return nil, unhandledError(args[0])
+		}
+
+		return []Target{{Kind: args[0], Name: args[1]}}, nil
+	}
+
+	if len(args) == 1 && IsNamespace(args[0]) {
+		return []Target{{Kind: args[0]}}, nil
+	}
+
+	output := make([]Target, 0, len(args))
+
+	for _, arg := range args {
+		kind, name, found := strings.Cut(arg, "/")
+		if !found || len(kind) == 0 || len(name) == 0 {
+			return nil, fmt.Errorf("`%s` is not in the `TYPE/NAME` form", arg)
+		}
+
+		if !IsTargetKind(kind) {
+			return nil, unhandledError(kind)
+		}
+
+		output = append(output, Target{Kind: kind, Name: name})
+	}
+
+	return output, nil
+}
+
+type ContainerType string
+
+const (
+	InitContainer      ContainerType = "init"
+	MainContainer      ContainerType = "main"
+	SidecarContainer   ContainerType = "sidecar"
+	EphemeralContainer ContainerType = "ephemeral"
+)
+
+var containerTypes = []ContainerType{InitContainer, MainContainer, SidecarContainer, EphemeralContainer}
+
+type TypedContainer struct {
+	Type ContainerType
+	v1.Container
+}
+
+func ParseContainerTypes(values []string) ([]ContainerType, error) {
+	output := make([]ContainerType, 0, len(values))
+
+	for _, value := range values {
+		containerType := ContainerType(strings.ToLower(value))
+		if !slices.Contains(containerTypes, containerType) {
+			return nil, fmt.Errorf("unknown container type `%s`, expected one of %v", value, containerTypes)
+		}
+
+		output = append(output, containerType)
+	}
+
+	return output, nil
+}
+
+// Containers lists every container of the spec, init ones first, with their type. Native sidecars are
+// init containers with an `Always` restart policy.
+func Containers(spec v1.PodSpec) []TypedContainer {
+	output := make([]TypedContainer, 0, len(spec.InitContainers)+len(spec.Containers)+len(spec.EphemeralContainers))
+
+	for _, container := range spec.InitContainers {
+		containerType := InitContainer
+		if container.RestartPolicy != nil && *container.RestartPolicy == v1.ContainerRestartPolicyAlways {
+			containerType = SidecarContainer
+		}
+
+		output = append(output, TypedContainer{Container: container, Type: containerType})
+	}
+
+	for _, container := range spec.Containers {
+		output = append(output, TypedContainer{Container: container, Type: MainContainer})
+	}
+
+	for _, container := range spec.EphemeralContainers {
+		output = append(output, TypedContainer{Container: v1.Container(container.EphemeralContainerCommon), Type: EphemeralContainer})
+	}
+
+	return output
+}
+
+func IsContainedSelected(container TypedContainer, filter *regexp.Regexp, types []ContainerType) bool {
+	if len(types) != 0 && !slices.Contains(types, container.Type) {
+		return false
+	}
+
 	if filter == nil {
 		return true
 	}
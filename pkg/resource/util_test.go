@@ -0,0 +1,258 @@
+package resource
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+func TestParseContainerTypes(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		values  []string
+		want    []ContainerType
+		wantErr bool
+	}{
+		"empty": {
+			nil,
+			[]ContainerType{},
+			false,
+		},
+		"valid": {
+			[]string{"main", "sidecar"},
+			[]ContainerType{MainContainer, SidecarContainer},
+			false,
+		},
+		"case insensitive": {
+			[]string{"Init", "EPHEMERAL"},
+			[]ContainerType{InitContainer, EphemeralContainer},
+			false,
+		},
+		"invalid": {
+			[]string{"main", "debug"},
+			nil,
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseContainerTypes(testCase.values)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("ParseContainerTypes() error = %v, want error %t", err, testCase.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("ParseContainerTypes() = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestContainers(t *testing.T) {
+	t.Parallel()
+
+	always := v1.ContainerRestartPolicyAlways
+
+	cases := map[string]struct {
+		spec v1.PodSpec
+		want []string
+	}{
+		"main": {
+			v1.PodSpec{
+				Containers: []v1.Container{{Name: "api"}},
+			},
+			[]string{"main/api"},
+		},
+		"init and sidecar": {
+			v1.PodSpec{
+				InitContainers: []v1.Container{{Name: "migrate"}, {Name: "proxy", RestartPolicy: &always}},
+				Containers:     []v1.Container{{Name: "api"}},
+			},
+			[]string{"init/migrate", "sidecar/proxy", "main/api"},
+		},
+		"ephemeral": {
+			v1.PodSpec{
+				Containers: []v1.Container{{Name: "api"}},
+				EphemeralContainers: []v1.EphemeralContainer{
+					{EphemeralContainerCommon: v1.EphemeralContainerCommon{Name: "debugger"}},
+				},
+			},
+			[]string{"main/api", "ephemeral/debugger"},
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			var got []string
+			for _, container := range Containers(testCase.spec) {
+				got = append(got, string(container.Type)+"/"+container.Name)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("Containers() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestIsContainedSelected(t *testing.T) {
+	t.Parallel()
+
+	type args struct {
+		container TypedContainer
+		filter    *regexp.Regexp
+		types     []ContainerType
+	}
+
+	sidecar := TypedContainer{Type: SidecarContainer, Container: v1.Container{Name: "proxy"}}
+	ephemeral := TypedContainer{Type: EphemeralContainer, Container: v1.Container{Name: "debugger"}}
+
+	cases := map[string]struct {
+		args args
+		want bool
+	}{
+		"no filter": {
+			args{
+				container: sidecar,
+			},
+			true,
+		},
+		"type selected": {
+			args{
+				container: sidecar,
+				types:     []ContainerType{MainContainer, SidecarContainer},
+			},
+			true,
+		},
+		"type not selected": {
+			args{
+				container: sidecar,
+				types:     []ContainerType{MainContainer},
+			},
+			false,
+		},
+		"ephemeral selected": {
+			args{
+				container: ephemeral,
+				types:     []ContainerType{EphemeralContainer},
+			},
+			true,
+		},
+		"ephemeral not selected": {
+			args{
+				container: ephemeral,
+				types:     []ContainerType{MainContainer, InitContainer},
+			},
+			false,
+		},
+		"name filtered": {
+			args{
+				container: sidecar,
+				filter:    regexp.MustCompile("^api$"),
+			},
+			false,
+		},
+		"name and type": {
+			args{
+				container: sidecar,
+				filter:    regexp.MustCompile("prox"),
+				types:     []ContainerType{SidecarContainer},
+			},
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := IsContainedSelected(testCase.args.container, testCase.args.filter, testCase.args.types); got != testCase.want {
+				t.Errorf("IsContainedSelected() = %t, want %t", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestParseTargets(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		args    []string
+		want    []Target
+		wantErr bool
+	}{
+		"legacy pair": {
+			[]string{"deploy", "api"},
+			[]Target{{Kind: "deploy", Name: "api"}},
+			false,
+		},
+		"namespace": {
+			[]string{"ns"},
+			[]Target{{Kind: "ns"}},
+			false,
+		},
+		"single": {
+			[]string{"deploy/api"},
+			[]Target{{Kind: "deploy", Name: "api"}},
+			false,
+		},
+		"several": {
+			[]string{"deploy/api", "deploy/worker"},
+			[]Target{{Kind: "deploy", Name: "api"}, {Kind: "deploy", Name: "worker"}},
+			false,
+		},
+		"mixed kinds": {
+			[]string{"deploy/api", "sts/db", "svc/front"},
+			[]Target{{Kind: "deploy", Name: "api"}, {Kind: "sts", Name: "db"}, {Kind: "svc", Name: "front"}},
+			false,
+		},
+		"missing name": {
+			[]string{"deploy/"},
+			nil,
+			true,
+		},
+		"missing kind": {
+			[]string{"/api"},
+			nil,
+			true,
+		},
+		"missing slash": {
+			[]string{"deploy/api", "worker"},
+			nil,
+			true,
+		},
+		"unknown kind": {
+			[]string{"deploy/api", "ingress/front"},
+			nil,
+			true,
+		},
+		"unknown legacy kind": {
+			[]string{"ingress", "front"},
+			nil,
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := ParseTargets(testCase.args)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("ParseTargets() error = %v, want error %t", err, testCase.wantErr)
+			}
+
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Errorf("ParseTargets() = %v, want %v", got, testCase.want)
+			}
+		})
+	}
+}
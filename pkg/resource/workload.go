@@ -0,0 +1,107 @@
+package resource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ViBiOh/kmux/pkg/client"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Workload is a resource running pods, with the spec of its pods
+type Workload struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Spec      v1.PodSpec
+}
+
+// ListWorkloads lists every workload of the namespace. Jobs, replicasets and pods owned by a listed workload are skipped,
+// their owner being listed, e.g. a static pod owned by its node is kept.
+func ListWorkloads(ctx context.Context, kube client.Kube) ([]Workload, error) {
+	var output []Workload
+
+	cronJobs, err := kube.BatchV1().CronJobs(kube.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list cronjobs: %w", err)
+	}
+
+	for _, item := range cronJobs.Items {
+		output = append(output, Workload{Kind: "cronjob", Namespace: item.Namespace, Name: item.Name, Spec: item.Spec.JobTemplate.Spec.Template.Spec})
+	}
+
+	daemonSets, err := kube.AppsV1().DaemonSets(kube.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list daemonsets: %w", err)
+	}
+
+	for _, item := range daemonSets.Items {
+		output = append(output, Workload{Kind: "daemonset", Namespace: item.Namespace, Name: item.Name, Spec: item.Spec.Template.Spec})
+	}
+
+	deployments, err := kube.AppsV1().Deployments(kube.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list deployments: %w", err)
+	}
+
+	for _, item := range deployments.Items {
+		output = append(output, Workload{Kind: "deployment", Namespace: item.Namespace, Name: item.Name, Spec: item.Spec.Template.Spec})
+	}
+
+	statefulSets, err := kube.AppsV1().StatefulSets(kube.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list statefulsets: %w", err)
+	}
+
+	for _, item := range statefulSets.Items {
+		output = append(output, Workload{Kind: "statefulset", Namespace: item.Namespace, Name: item.Name, Spec: item.Spec.Template.Spec})
+	}
+
+	jobs, err := kube.BatchV1().Jobs(kube.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+
+	for _, item := range jobs.Items {
+		if !ownedByWorkload(item.OwnerReferences) {
+			output = append(output, Workload{Kind: "job", Namespace: item.Namespace, Name: item.Name, Spec: item.Spec.Template.Spec})
+		}
+	}
+
+	replicaSets, err := kube.AppsV1().ReplicaSets(kube.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list replicasets: %w", err)
+	}
+
+	for _, item := range replicaSets.Items {
+		if !ownedByWorkload(item.OwnerReferences) {
+			output = append(output, Workload{Kind: "replicaset", Namespace: item.Namespace, Name: item.Name, Spec: item.Spec.Template.Spec})
+		}
+	}
+
+	pods, err := kube.CoreV1().Pods(kube.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+
+	for _, item := range pods.Items {
+		if !ownedByWorkload(item.OwnerReferences) {
+			output = append(output, Workload{Kind: "pod", Namespace: item.Namespace, Name: item.Name, Spec: item.Spec})
+		}
+	}
+
+	return output, nil
+}
+
+// ownedByWorkload checks if one of the owners is a kind listed by ListWorkloads
+func ownedByWorkload(owners []metav1.OwnerReference) bool {
+	for _, owner := range owners {
+		switch owner.Kind {
+		case "CronJob", "DaemonSet", "Deployment", "StatefulSet", "Job", "ReplicaSet":
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,51 @@
+package resource
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOwnedByWorkload(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		owners []metav1.OwnerReference
+		want   bool
+	}{
+		"standalone": {
+			nil,
+			false,
+		},
+		"replicaset": {
+			[]metav1.OwnerReference{{Kind: "ReplicaSet", Name: "api-7d9f"}},
+			true,
+		},
+		"cronjob": {
+			[]metav1.OwnerReference{{Kind: "CronJob", Name: "backup"}},
+			true,
+		},
+		"static pod": {
+			[]metav1.OwnerReference{{Kind: "Node", Name: "control-plane"}},
+			false,
+		},
+		"unlisted kind": {
+			[]metav1.OwnerReference{{Kind: "Rollout", Name: "api"}},
+			false,
+		},
+		"several owners": {
+			[]metav1.OwnerReference{{Kind: "Rollout", Name: "api"}, {Kind: "Job", Name: "migrate"}},
+			true,
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := ownedByWorkload(testCase.owners); got != testCase.want {
+				t.Errorf("ownedByWorkload() = %t, want %t", got, testCase.want)
+			}
+		})
+	}
+}
@@ -47,6 +47,25 @@ func (t *Table) Format(cells []Cell) string {
 	return builder.String()
 }
 
+// Render formats rows aligned on the widest cell of each column, one row per line
+func Render(rows [][]Cell) string {
+	renderTable := New(nil)
+
+	// first pass for computing widths of every column
+	for _, row := range rows {
+		renderTable.Format(row)
+	}
+
+	var builder strings.Builder
+
+	for _, row := range rows {
+		builder.WriteString(renderTable.Format(row))
+		builder.WriteString("\n")
+	}
+
+	return builder.String()
+}
+
 type Printer func(io.Writer, string, ...any) (int, error)
 
 type Cell struct {
@@ -0,0 +1,42 @@
+package table
+
+import "testing"
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]struct {
+		rows [][]Cell
+		want string
+	}{
+		"empty": {
+			nil,
+			"",
+		},
+		"aligned on widest cell": {
+			[][]Cell{
+				{NewCell("NAME"), NewCell("IMAGE")},
+				{NewCell("api"), NewCell("api:1")},
+				{NewCell("worker"), NewCell("worker:12")},
+			},
+			"NAME   IMAGE    \napi    api:1    \nworker worker:12\n",
+		},
+		"shorter row": {
+			[][]Cell{
+				{NewCell("NAME"), NewCell("IMAGE")},
+				{NewCell("api")},
+			},
+			"NAME IMAGE\napi \n",
+		},
+	}
+
+	for intention, testCase := range cases {
+		t.Run(intention, func(t *testing.T) {
+			t.Parallel()
+
+			if got := Render(testCase.rows); got != testCase.want {
+				t.Errorf("Render() = %q, want %q", got, testCase.want)
+			}
+		})
+	}
+}